@@ -0,0 +1,161 @@
+package jsond
+
+// Builder builds a JSON value by editing it in place, which avoids the copies that Node.Set makes
+// to keep Nodes immutable. It is meant for assembling new values, such as responses, from scratch.
+//
+// Builder.Set has the same path semantics and errors as Node.Set. The first error stops the build:
+// later calls to Set are ignored, and Freeze returns a Node with the error.
+// A Builder is not safe for concurrent use.
+type Builder struct {
+	value  jsonvalue
+	err    error
+	frozen bool // the value is shared with a Node returned by Freeze
+}
+
+// NewBuilder returns a new Builder whose value is an empty object.
+func NewBuilder() *Builder {
+	return &Builder{
+		value: map[string]any{},
+	}
+}
+
+// Set sets the value at the given property path, in place. If no properties are provided, it replaces the whole value.
+// Nodes passed as the value, or contained in it, are copied, so that later edits do not change them.
+func (b *Builder) Set(value any, props ...any) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if b.frozen {
+		b.value = copyValue(b.value)
+		b.frozen = false
+	}
+
+	jv, err := getOwnedJSONValue(value)
+	if err == nil {
+		if updated, ok := setInPlace(b.value, props, jv); ok {
+			b.value = updated
+			return b
+		}
+	}
+
+	// Node.Set reports the same error as the Builder should, and handles the cases setInPlace does not.
+	node := (&Node{value: b.value, path: jsonpath{}}).Set(value, props...)
+	if node.err != nil {
+		b.err = node.err
+		return b
+	}
+	b.value = node.value
+	return b
+}
+
+// Err returns the first error that occurred while building.
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// Freeze returns the built value as a Node, without copying it.
+// The Builder can still be used afterwards: its next Set copies the value first, so that the Node is never modified.
+func (b *Builder) Freeze() *Node {
+	b.frozen = true
+
+	return &Node{
+		parent: nil,
+		value:  b.value,
+		path:   jsonpath{},
+		err:    b.err,
+	}
+}
+
+// setInPlace sets v at props within container, modifying the containers along the path, and returns the updated container.
+// Arrays may be reallocated when they grow, so callers must store the returned container.
+// It returns false, without modifying anything, if the path does not refer to an existing container.
+func setInPlace(container jsonvalue, props []any, v jsonvalue) (jsonvalue, bool) {
+	if len(props) == 0 {
+		return v, true
+	}
+	if _, ok := props[0].(Selector); ok {
+		return nil, false
+	}
+	prop, err := getProperty(props[0])
+	if err != nil {
+		return nil, false
+	}
+	last := len(props) == 1
+
+	switch c := container.(type) {
+	case []any:
+		idx, ok := prop.(arrayIndex)
+		if !ok {
+			return nil, false
+		}
+		i := int(idx)
+		if i < 0 {
+			i += len(c)
+		}
+
+		switch {
+		case i < 0:
+			return nil, false
+		case i >= len(c) && last:
+			c = append(c, make([]any, i+1-len(c))...)
+			c[i] = v
+			return c, true
+		case i >= len(c):
+			return nil, false
+		case last:
+			c[i] = v
+			return c, true
+		}
+
+		child, ok := setInPlace(c[i], props[1:], v)
+		if !ok {
+			return nil, false
+		}
+		c[i] = child
+		return c, true
+
+	case map[string]any:
+		key, ok := prop.(objectKey)
+		if !ok {
+			return nil, false
+		}
+		if last {
+			c[string(key)] = v
+			return c, true
+		}
+
+		elem, exists := c[string(key)]
+		if !exists {
+			return nil, false
+		}
+		child, ok := setInPlace(elem, props[1:], v)
+		if !ok {
+			return nil, false
+		}
+		c[string(key)] = child
+		return c, true
+
+	default:
+		return nil, false
+	}
+}
+
+// copyValue returns a deep copy of v.
+func copyValue(v jsonvalue) jsonvalue {
+	switch t := v.(type) {
+	case []any:
+		array := make([]any, len(t))
+		for i, elem := range t {
+			array[i] = copyValue(elem)
+		}
+		return array
+	case map[string]any:
+		object := make(map[string]any, len(t))
+		for k, elem := range t {
+			object[k] = copyValue(elem)
+		}
+		return object
+	default:
+		return v
+	}
+}
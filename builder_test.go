@@ -0,0 +1,133 @@
+package jsond
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestBuilderSet(t *testing.T) {
+	src := `{"a": {"b": [1, 2]}, "n": null, "s": "str"}`
+
+	// the Builder must produce the same values and errors as Node.Set
+	tests := []struct {
+		value any
+		props []any
+	}{
+		{value: 1, props: []any{"x"}},
+		{value: 3, props: []any{"a", "b", 0}},
+		{value: 3, props: []any{"a", "b", -1}},
+		{value: 3, props: []any{"a", "b", 2}},
+		{value: 3, props: []any{"a", "b", 4}},
+		{value: 3, props: []any{"a", "b", -3}},
+		{value: map[string]any{"c": true}, props: []any{"a", "b"}},
+		{value: []int{1}, props: []any{}},
+		{value: 1, props: []any{"x", "y"}},
+		{value: 1, props: []any{"x", "y", "z"}},
+		{value: 1, props: []any{"n", "y"}},
+		{value: 1, props: []any{"s", "y"}},
+		{value: 1, props: []any{"a", 0}},
+		{value: 1, props: []any{"a", "b", "c"}},
+		{value: 1, props: []any{"a", All, "c"}},
+		{value: func() {}, props: []any{"f"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.props), func(t *testing.T) {
+			want, wantErr := Parse([]byte(src)).Set(tt.value, tt.props...).Marshal()
+
+			b := NewBuilder().Set(Parse([]byte(src)))
+			got, gotErr := b.Set(tt.value, tt.props...).Freeze().Marshal()
+
+			if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+				t.Fatalf("\ngot  %v\nwant %v", gotErr, wantErr)
+			}
+			if string(got) != string(want) {
+				t.Errorf("\ngot  %s\nwant %s", got, want)
+			}
+			if fmt.Sprint(b.Err()) != fmt.Sprint(wantErr) {
+				t.Errorf("\ngot  %v\nwant %v", b.Err(), wantErr)
+			}
+		})
+	}
+}
+
+func TestBuilderDoesNotModifyNodes(t *testing.T) {
+	item := Parse([]byte(`{"id": 1, "tags": ["a"]}`))
+
+	b := NewBuilder().
+		Set([]any{}, "items").
+		Set(item, "items", 0).
+		Set(2, "items", 0, "id").
+		Set("b", "items", 0, "tags", 1)
+	frozen := b.Freeze()
+
+	b.Set(3, "items", 0, "id").Set("x", "items", 1)
+
+	for _, tt := range []struct {
+		node *Node
+		want string
+	}{
+		{node: item, want: `{"id":1,"tags":["a"]}`},
+		{node: frozen, want: `{"items":[{"id":2,"tags":["a","b"]}]}`},
+		{node: b.Freeze(), want: `{"items":[{"id":3,"tags":["a","b"]},"x"]}`},
+	} {
+		got, err := tt.node.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("\ngot  %s\nwant %s", got, tt.want)
+		}
+	}
+}
+
+func TestBuilderDoesNotModifyNestedNodes(t *testing.T) {
+	item := Parse([]byte(`{"id": 1}`))
+	type wrapper struct {
+		Item *Node `json:"item"`
+	}
+
+	b := NewBuilder().
+		Set(map[string]any{"item": item}, "map").
+		Set([]*Node{item}, "slice").
+		Set(wrapper{Item: item}, "struct").
+		Set(99, "map", "item", "id").
+		Set(99, "slice", 0, "id").
+		Set(99, "struct", "item", "id")
+
+	got, _ := item.Marshal()
+	if string(got) != `{"id":1}` {
+		t.Errorf("\ngot  %s\nwant %s", got, `{"id":1}`)
+	}
+	got, _ = b.Freeze().Marshal()
+	if want := `{"map":{"item":{"id":99}},"slice":[{"id":99}],"struct":{"item":{"id":99}}}`; string(got) != want {
+		t.Errorf("\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestBuilderStopsAtFirstError(t *testing.T) {
+	b := NewBuilder().
+		Set(1, "missing", "a").
+		Set(2, "b")
+
+	want := "cannot set properties of undefined (setting 'a') at $['missing']['a']"
+	if err := b.Err(); err == nil || err.Error() != want {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+	if err := b.Freeze().Error(); err != b.Err() {
+		t.Errorf("\ngot  %v\nwant %v", err, b.Err())
+	}
+}
+
+func TestBuilderEditsInPlace(t *testing.T) {
+	b := NewBuilder().Set([]any{}, "items")
+	object := b.value.(map[string]any)
+
+	b.Set(map[string]any{}, "items", 0).Set(true, "items", 0, "ok")
+
+	got, _ := json.Marshal(object)
+	if want := `{"items":[{"ok":true}]}`; string(got) != want {
+		t.Errorf("\ngot  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,171 @@
+package jsond
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Canonical returns the Node's value serialized in the JSON Canonicalization Scheme (RFC 8785).
+// The output is byte-for-byte deterministic, which makes it suitable for signing and hashing.
+func (n *Node) Canonical() ([]byte, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeCanonical(buf, n.path, n.value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Hash writes the canonical form of the Node's value into h and returns the resulting sum.
+func (n *Node) Hash(h hash.Hash) ([]byte, error) {
+	data, err := n.Canonical()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := h.Write(data); err != nil {
+		return nil, newInternalError(n.path, err)
+	}
+	return h.Sum(nil), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, path jsonpath, v jsonvalue) error {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(t))
+	case float64, json.Number, int64, uint64:
+		f, err := numberFloat64(t)
+		if err != nil {
+			return newMarshalError(path, err)
+		}
+		s, err := formatCanonicalNumber(f)
+		if err != nil {
+			return newMarshalError(path, err)
+		}
+		buf.WriteString(s)
+	case string:
+		if err := writeCanonicalString(buf, t); err != nil {
+			return newMarshalError(path, err)
+		}
+	case []any:
+		buf.WriteByte('[')
+		for i, elem := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, path.append(arrayIndex(i)), elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return lessUTF16(keys[i], keys[j])
+		})
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalString(buf, k); err != nil {
+				return newMarshalError(path.append(objectKey(k)), err)
+			}
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, path.append(objectKey(k)), t[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return newInternalError(path, fmt.Errorf("invalid jsonvalue. v=%v", v))
+	}
+	return nil
+}
+
+// formatCanonicalNumber formats f as ECMAScript's Number.prototype.toString does,
+// which is the number serialization mandated by RFC 8785.
+func formatCanonicalNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("unsupported number: %v", f)
+	}
+	if f == 0 {
+		// both 0 and -0 are serialized as "0"
+		return "0", nil
+	}
+
+	abs := math.Abs(f)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+
+	// strconv pads the exponent to two digits (e.g. "1e-07"), ECMAScript does not.
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(s, "e")
+	sign, digits := exp[:1], strings.TrimLeft(exp[1:], "0")
+	return mantissa + "e" + sign + digits, nil
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) error {
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("invalid UTF-8 in string: %q", s)
+	}
+
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return nil
+}
+
+// lessUTF16 reports whether a sorts before b when both are compared as arrays of UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
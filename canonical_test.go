@@ -0,0 +1,103 @@
+package jsond
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatCanonicalNumber(t *testing.T) {
+
+	tests := []struct {
+		src  float64
+		want string
+	}{
+		{src: 0, want: "0"},
+		{src: math.Copysign(0, -1), want: "0"},
+		{src: 1, want: "1"},
+		{src: -1.5, want: "-1.5"},
+		{src: 4.50, want: "4.5"},
+		{src: 2e-3, want: "0.002"},
+		{src: 0.000001, want: "0.000001"},
+		{src: 1e-7, want: "1e-7"},
+		{src: 1e-27, want: "1e-27"},
+		{src: 1e20, want: "100000000000000000000"},
+		{src: 1e21, want: "1e+21"},
+		{src: 1e30, want: "1e+30"},
+		{src: 333333333.33333329, want: "333333333.3333333"},
+		{src: 9007199254740992, want: "9007199254740992"},
+		{src: 5e-324, want: "5e-324"},
+		{src: 1.7976931348623157e308, want: "1.7976931348623157e+308"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := formatCanonicalNumber(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonical(t *testing.T) {
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "rfc8785 example",
+			src: `{
+				"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+				"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+				"literals": [null, true, false]
+			}`,
+			want: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			name: "utf-16 key order",
+			src: `{
+				"\u20ac": "Euro Sign",
+				"\r": "Carriage Return",
+				"\ufb33": "Hebrew Letter Dalet With Dagesh",
+				"1": "One",
+				"\ud83d\ude00": "Emoji: Grinning Face",
+				"\u0080": "Control",
+				"\u00f6": "Latin Small Letter O With Diaeresis"
+			}`,
+			want: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{
+			name: "no html escaping",
+			src:  `["<a href=\"x\">&</a>", "\u2028"]`,
+			want: "[\"<a href=\\\"x\\\">&</a>\",\"\u2028\"]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.src)).Canonical()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalError(t *testing.T) {
+	_, err := Parse([]byte(`{"a":[1]}`)).
+		Set(math.NaN(), "a", 0).
+		Canonical()
+
+	want := "unsupported number: NaN at $['a'][0]"
+	if err == nil || err.Error() != want {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+}
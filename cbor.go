@@ -0,0 +1,604 @@
+package jsond
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// CBOR major types (RFC 8949 Section 3.1)
+const (
+	cborUint byte = iota
+	cborNegint
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+// CBOROption configures how ParseCBOR decodes CBOR data.
+type CBOROption func(*cborConfig)
+
+type cborConfig struct {
+	maxDepth  int
+	maxLength int
+	bytes     func([]byte) any
+}
+
+// CBORMaxDepth sets the maximum nesting depth of arrays, maps and tags. The default is 10000.
+func CBORMaxDepth(depth int) CBOROption {
+	return func(c *cborConfig) {
+		c.maxDepth = depth
+	}
+}
+
+// CBORMaxLength sets the maximum length of a single string, array or map, including indefinite-length ones.
+// By default, lengths are only limited by the size of the input.
+func CBORMaxLength(length int) CBOROption {
+	return func(c *cborConfig) {
+		c.maxLength = length
+	}
+}
+
+// CBORByteStrings sets the function that maps a byte string to a JSON value.
+// By default, byte strings become base64 strings (RFC 4648 standard encoding with padding),
+// or base64url / base16 strings if they are tagged with an expected conversion (tags 21 to 23).
+func CBORByteStrings(f func(b []byte) any) CBOROption {
+	return func(c *cborConfig) {
+		c.bytes = f
+	}
+}
+
+// ParseCBOR decodes a single CBOR data item (RFC 8949) and returns a Node representing its structure.
+//
+// Integers are decoded as int64 (or uint64 if they do not fit), floating-point numbers as float64,
+// and integers that fit neither, such as bignums, as json.Number.
+// Map keys must be text strings or integers. Tags are resolved as follows:
+//   - bignums (tags 2 and 3) and decimal fractions (tag 4) become numbers
+//   - expected conversions (tags 21 to 23) select the encoding of the byte strings they contain
+//   - other tags, such as date/time strings (tag 0) and epoch times (tag 1), are replaced by their content
+func ParseCBOR(data []byte, opts ...CBOROption) *Node {
+	cfg := cborConfig{
+		maxDepth: maxParseDepth,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	d := &cborDecoder{
+		data: data,
+		cfg:  cfg,
+	}
+	path := jsonpath{}
+
+	value, err := d.decode(path, 0, base64.StdEncoding.EncodeToString)
+	if err == nil && d.offset < len(d.data) {
+		err = newDecodeError(path, d.offset, "unexpected data after top-level CBOR item")
+	}
+	if err != nil {
+		value = nil
+	}
+
+	return &Node{
+		parent: nil,
+		value:  value,
+		path:   path,
+		err:    err,
+	}
+}
+
+type cborDecoder struct {
+	data   []byte
+	offset int
+	cfg    cborConfig
+}
+
+// decode decodes the next data item.
+// encodeBytes is the encoding for byte strings selected by an enclosing expected conversion tag.
+func (d *cborDecoder) decode(path jsonpath, depth int, encodeBytes func([]byte) string) (jsonvalue, error) {
+	if depth > d.cfg.maxDepth {
+		return nil, newDecodeError(path, d.offset, "exceeded max depth")
+	}
+
+	start := d.offset
+	major, info, arg, err := d.readHead(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborUint:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+
+	case cborNegint:
+		if arg > math.MaxInt64 {
+			n := new(big.Int).SetUint64(arg)
+			return json.Number(n.Neg(n).Sub(n, big.NewInt(1)).String()), nil
+		}
+		return -1 - int64(arg), nil
+
+	case cborBytes:
+		b, err := d.readString(path, start, major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		if d.cfg.bytes != nil {
+			v, err := getJSONValue(d.cfg.bytes(b))
+			if err != nil {
+				return nil, newDecodeError(path, start, err.Error())
+			}
+			return v, nil
+		}
+		return encodeBytes(b), nil
+
+	case cborText:
+		b, err := d.readString(path, start, major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			return nil, newDecodeError(path, start, "invalid UTF-8 in text string")
+		}
+		return string(b), nil
+
+	case cborArray:
+		array := []any{}
+		for i := 0; info == 31 || i < int(arg); i++ {
+			if info == 31 && d.readBreak() {
+				break
+			}
+			if err := d.checkIndefiniteLength(path, start, info, i); err != nil {
+				return nil, err
+			}
+			v, err := d.decode(path.append(arrayIndex(i)), depth+1, encodeBytes)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, v)
+		}
+		return array, nil
+
+	case cborMap:
+		object := map[string]any{}
+		for i := 0; info == 31 || i < int(arg); i++ {
+			if info == 31 && d.readBreak() {
+				break
+			}
+			if err := d.checkIndefiniteLength(path, start, info, i); err != nil {
+				return nil, err
+			}
+			keyStart := d.offset
+			k, err := d.decode(path, depth+1, encodeBytes)
+			if err != nil {
+				return nil, err
+			}
+			key, ok := integerKeyString(k)
+			if !ok {
+				return nil, newDecodeError(path, keyStart, fmt.Sprintf("unsupported map key type %s", getTypeString(k)))
+			}
+			v, err := d.decode(path.append(objectKey(key)), depth+1, encodeBytes)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = v
+		}
+		return object, nil
+
+	case cborTag:
+		return d.decodeTag(path, depth, start, arg, encodeBytes)
+
+	default: // cborSimple
+		return d.decodeSimple(path, start, info, arg)
+	}
+}
+
+func (d *cborDecoder) decodeTag(path jsonpath, depth int, start int, tag uint64, encodeBytes func([]byte) string) (jsonvalue, error) {
+	switch tag {
+	case 2, 3: // unsigned / negative bignum
+		// the content is read as raw bytes, since a nested tag or CBORByteStrings would change how a byte string is decoded
+		contentStart := d.offset
+		major, info, arg, err := d.readHead(path)
+		if err != nil {
+			return nil, err
+		}
+		if major != cborBytes {
+			return nil, newDecodeError(path, start, "bignum content must be a byte string")
+		}
+		b, err := d.readString(path, contentStart, major, info, arg)
+		if err != nil {
+			return nil, err
+		}
+		n := new(big.Int).SetBytes(b)
+		if tag == 3 {
+			n.Neg(n).Sub(n, big.NewInt(1))
+		}
+		return json.Number(n.String()), nil
+
+	case 4: // decimal fraction [exponent, mantissa]
+		v, err := d.decode(path, depth+1, encodeBytes)
+		if err != nil {
+			return nil, err
+		}
+		pair, ok := v.([]any)
+		if !ok || len(pair) != 2 || !isInteger(pair[0]) || !isInteger(pair[1]) {
+			return nil, newDecodeError(path, start, "decimal fraction must be an array of two integers")
+		}
+		return json.Number(fmt.Sprintf("%ve%v", pair[1], pair[0])), nil
+
+	case 21:
+		return d.decode(path, depth+1, base64.RawURLEncoding.EncodeToString)
+	case 22:
+		return d.decode(path, depth+1, base64.StdEncoding.EncodeToString)
+	case 23:
+		return d.decode(path, depth+1, hex.EncodeToString)
+
+	default:
+		return d.decode(path, depth+1, encodeBytes)
+	}
+}
+
+func (d *cborDecoder) decodeSimple(path jsonpath, start int, info byte, arg uint64) (jsonvalue, error) {
+	var f float64
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23: // null, undefined
+		return nil, nil
+	case 25:
+		f = float16ToFloat64(uint16(arg))
+	case 26:
+		f = float64(math.Float32frombits(uint32(arg)))
+	case 27:
+		f = math.Float64frombits(arg)
+	case 31:
+		return nil, newDecodeError(path, start, "unexpected break")
+	default:
+		return nil, newDecodeError(path, start, fmt.Sprintf("unsupported simple value %d", arg))
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, newDecodeError(path, start, fmt.Sprintf("%v cannot be represented in JSON", f))
+	}
+	return f, nil
+}
+
+// readHead reads the initial byte and argument of a data item.
+// For indefinite-length items, info is 31 and arg is 0.
+func (d *cborDecoder) readHead(path jsonpath) (major byte, info byte, arg uint64, err error) {
+	start := d.offset
+	if d.offset >= len(d.data) {
+		return 0, 0, 0, newDecodeError(path, start, "unexpected end of CBOR input")
+	}
+
+	major, info = d.data[d.offset]>>5, d.data[d.offset]&0x1f
+	d.offset++
+
+	size := 0
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		size = 1 << (info - 24)
+	case info == 31 && major != cborUint && major != cborNegint && major != cborTag:
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, newDecodeError(path, start, fmt.Sprintf("invalid additional information %d", info))
+	}
+
+	if d.offset+size > len(d.data) {
+		return 0, 0, 0, newDecodeError(path, start, "unexpected end of CBOR input")
+	}
+	for _, b := range d.data[d.offset : d.offset+size] {
+		arg = arg<<8 | uint64(b)
+	}
+	d.offset += size
+
+	if major == cborArray || major == cborMap {
+		// every element needs at least one byte, which bounds the length by the remaining input
+		if err := d.checkLength(path, start, arg, uint64(len(d.data)-d.offset)); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	return major, info, arg, nil
+}
+
+// checkIndefiniteLength checks the length of an indefinite-length array or map before its element i is read,
+// since its length is not known from its head.
+func (d *cborDecoder) checkIndefiniteLength(path jsonpath, start int, info byte, i int) error {
+	if info != 31 {
+		return nil
+	}
+	return d.checkLength(path, start, uint64(i)+1, math.MaxUint64)
+}
+
+func (d *cborDecoder) checkLength(path jsonpath, start int, length uint64, remaining uint64) error {
+	if d.cfg.maxLength > 0 && length > uint64(d.cfg.maxLength) {
+		return newDecodeError(path, start, fmt.Sprintf("length %d exceeds the maximum of %d", length, d.cfg.maxLength))
+	}
+	if length > remaining {
+		return newDecodeError(path, start, "unexpected end of CBOR input")
+	}
+	return nil
+}
+
+// readString reads the content of a byte or text string, which may be split into chunks.
+func (d *cborDecoder) readString(path jsonpath, start int, major byte, info byte, length uint64) ([]byte, error) {
+	if info != 31 {
+		if err := d.checkLength(path, start, length, uint64(len(d.data)-d.offset)); err != nil {
+			return nil, err
+		}
+		b := d.data[d.offset : d.offset+int(length)]
+		d.offset += int(length)
+		return b, nil
+	}
+
+	buf := []byte{}
+	for !d.readBreak() {
+		chunkStart := d.offset
+		chunkMajor, chunkInfo, chunkLength, err := d.readHead(path)
+		if err != nil {
+			return nil, err
+		}
+		if chunkMajor != major || chunkInfo == 31 {
+			return nil, newDecodeError(path, chunkStart, "invalid chunk in indefinite-length string")
+		}
+		chunk, err := d.readString(path, chunkStart, chunkMajor, chunkInfo, chunkLength)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, chunk...)
+		if err := d.checkLength(path, start, uint64(len(buf)), math.MaxUint64); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// readBreak consumes the "break" stop code of an indefinite-length item, if present.
+func (d *cborDecoder) readBreak() bool {
+	if d.offset < len(d.data) && d.data[d.offset] == 0xff {
+		d.offset++
+		return true
+	}
+	return false
+}
+
+func integerKeyString(k jsonvalue) (string, bool) {
+	switch t := k.(type) {
+	case string:
+		return t, true
+	case int64:
+		return strconv.FormatInt(t, 10), true
+	case uint64:
+		return strconv.FormatUint(t, 10), true
+	default:
+		return "", false
+	}
+}
+
+func isInteger(v jsonvalue) bool {
+	switch v.(type) {
+	case int64, uint64:
+		return true
+	case json.Number:
+		_, ok := new(big.Int).SetString(fmt.Sprint(v), 10)
+		return ok
+	default:
+		return false
+	}
+}
+
+// float16ToFloat64 converts an IEEE 754 half-precision float to a float64.
+func float16ToFloat64(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1.0
+	}
+	exp := int(h>>10) & 0x1f
+	frac := float64(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(frac, -24)
+	case 0x1f:
+		if frac == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	default:
+		return sign * math.Ldexp(frac+1024, exp-25)
+	}
+}
+
+// MarshalCBOR encodes the Node's value as CBOR (RFC 8949).
+// Integers (int64, uint64 and integral json.Number values) are encoded as CBOR integers, or as bignums
+// if they do not fit in 64 bits, and other numbers as the shortest float that preserves their value.
+// Map keys are sorted as required by the core deterministic encoding.
+func (n *Node) MarshalCBOR() ([]byte, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeCBOR(buf, n.path, n.value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCBOR(buf *bytes.Buffer, path jsonpath, v jsonvalue) error {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteByte(cborSimple<<5 | 22)
+	case bool:
+		if t {
+			buf.WriteByte(cborSimple<<5 | 21)
+		} else {
+			buf.WriteByte(cborSimple<<5 | 20)
+		}
+	case int64:
+		if t < 0 {
+			writeCBORHead(buf, cborNegint, uint64(-1-t))
+		} else {
+			writeCBORHead(buf, cborUint, uint64(t))
+		}
+	case uint64:
+		writeCBORHead(buf, cborUint, t)
+	case float64:
+		return writeCBORFloat(buf, path, t)
+	case json.Number:
+		return writeCBORNumber(buf, path, t)
+	case string:
+		writeCBORHead(buf, cborText, uint64(len(t)))
+		buf.WriteString(t)
+	case []any:
+		writeCBORHead(buf, cborArray, uint64(len(t)))
+		for i, elem := range t {
+			if err := writeCBOR(buf, path.append(arrayIndex(i)), elem); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		type entry struct {
+			key   []byte
+			value jsonvalue
+			path  jsonpath
+		}
+		entries := make([]entry, 0, len(t))
+		for k, elem := range t {
+			key := &bytes.Buffer{}
+			writeCBORHead(key, cborText, uint64(len(k)))
+			key.WriteString(k)
+			entries = append(entries, entry{key: key.Bytes(), value: elem, path: path.append(objectKey(k))})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i].key, entries[j].key) < 0
+		})
+
+		writeCBORHead(buf, cborMap, uint64(len(t)))
+		for _, e := range entries {
+			buf.Write(e.key)
+			if err := writeCBOR(buf, e.path, e.value); err != nil {
+				return err
+			}
+		}
+	default:
+		return newInternalError(path, fmt.Errorf("invalid jsonvalue. v=%v", v))
+	}
+	return nil
+}
+
+func writeCBORHead(buf *bytes.Buffer, major byte, arg uint64) {
+	switch {
+	case arg < 24:
+		buf.WriteByte(major<<5 | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.Write([]byte{major<<5 | 24, byte(arg)})
+	case arg <= math.MaxUint16:
+		buf.WriteByte(major<<5 | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(arg)))
+	case arg <= math.MaxUint32:
+		buf.WriteByte(major<<5 | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(arg)))
+	default:
+		buf.WriteByte(major<<5 | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, arg))
+	}
+}
+
+func writeCBORFloat(buf *bytes.Buffer, path jsonpath, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return newMarshalError(path, fmt.Errorf("unsupported number: %v", f))
+	}
+
+	if h, ok := float64ToFloat16(f); ok {
+		buf.WriteByte(cborSimple<<5 | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, h))
+		return nil
+	}
+	if f32 := float32(f); float64(f32) == f {
+		buf.WriteByte(cborSimple<<5 | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f32)))
+		return nil
+	}
+	buf.WriteByte(cborSimple<<5 | 27)
+	buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+	return nil
+}
+
+// writeCBORNumber writes a json.Number as an integer if it is integral, and as a float otherwise.
+func writeCBORNumber(buf *bytes.Buffer, path jsonpath, n json.Number) error {
+	if i, ok := new(big.Int).SetString(string(n), 10); ok {
+		switch {
+		case i.IsInt64():
+			return writeCBOR(buf, path, i.Int64())
+		case i.IsUint64():
+			return writeCBOR(buf, path, i.Uint64())
+		case i.Sign() > 0:
+			writeCBORHead(buf, cborTag, 2)
+		default:
+			writeCBORHead(buf, cborTag, 3)
+			i.Neg(i).Sub(i, big.NewInt(1))
+		}
+		b := i.Bytes()
+		writeCBORHead(buf, cborBytes, uint64(len(b)))
+		buf.Write(b)
+		return nil
+	}
+
+	f, err := numberFloat64(n)
+	if err != nil {
+		return newMarshalError(path, err)
+	}
+	return writeCBORFloat(buf, path, f)
+}
+
+// float64ToFloat16 converts f to an IEEE 754 half-precision float if that preserves its value.
+func float64ToFloat16(f float64) (uint16, bool) {
+	if f == 0 {
+		if math.Signbit(f) {
+			return 0x8000, true
+		}
+		return 0, true
+	}
+
+	frac, exp := math.Frexp(math.Abs(f)) // f = frac * 2^exp, 0.5 <= frac < 1
+	sign := uint16(0)
+	if f < 0 {
+		sign = 0x8000
+	}
+
+	var h uint16
+	switch {
+	case exp > 16:
+		return 0, false
+	case exp >= -13: // normal
+		m := frac*2048 - 1024
+		if m != math.Trunc(m) {
+			return 0, false
+		}
+		h = sign | uint16(exp+14)<<10 | uint16(m)
+	default: // subnormal
+		m := math.Ldexp(frac, exp+24)
+		if m != math.Trunc(m) || m >= 1024 {
+			return 0, false
+		}
+		h = sign | uint16(m)
+	}
+	return h, float16ToFloat64(h) == f
+}
@@ -0,0 +1,229 @@
+package jsond
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseCBOR(t *testing.T) {
+
+	// examples from RFC 8949 Appendix A
+	tests := []struct {
+		src  string
+		want jsonvalue
+	}{
+		{src: "00", want: int64(0)},
+		{src: "17", want: int64(23)},
+		{src: "1818", want: int64(24)},
+		{src: "1903e8", want: int64(1000)},
+		{src: "1b000000e8d4a51000", want: int64(1000000000000)},
+		{src: "1bffffffffffffffff", want: uint64(18446744073709551615)},
+		{src: "c249010000000000000000", want: json.Number("18446744073709551616")},
+		{src: "3bffffffffffffffff", want: json.Number("-18446744073709551616")},
+		{src: "c349010000000000000000", want: json.Number("-18446744073709551617")},
+		{src: "c24101", want: json.Number("1")},
+		{src: "c25f41014100ff", want: json.Number("256")},
+		{src: "20", want: int64(-1)},
+		{src: "3903e7", want: int64(-1000)},
+		{src: "f90000", want: float64(0)},
+		{src: "f93c00", want: float64(1)},
+		{src: "f93e00", want: 1.5},
+		{src: "f97bff", want: float64(65504)},
+		{src: "f90001", want: 5.960464477539063e-8},
+		{src: "f9c400", want: float64(-4)},
+		{src: "fa47c35000", want: float64(100000)},
+		{src: "fb3ff199999999999a", want: 1.1},
+		{src: "fb7e37e43c8800759c", want: 1e300},
+		{src: "c48221196ab3", want: json.Number("27315e-2")},
+		{src: "f4", want: false},
+		{src: "f5", want: true},
+		{src: "f6", want: nil},
+		{src: "f7", want: nil},
+		{src: "c074323031332d30332d32315432303a30343a30305a", want: "2013-03-21T20:04:00Z"},
+		{src: "c11a514b67b0", want: int64(1363896240)},
+		{src: "d74401020304", want: "01020304"},
+		{src: "d5820141ff", want: []any{int64(1), "_w"}},
+		{src: "4401020304", want: "AQIDBA=="},
+		{src: "6449455446", want: "IETF"},
+		{src: "62c3bc", want: "ü"},
+		{src: "80", want: []any{}},
+		{src: "83010203", want: []any{int64(1), int64(2), int64(3)}},
+		{src: "a201020304", want: map[string]any{"1": int64(2), "3": int64(4)}},
+		{src: "a26161016162820203", want: map[string]any{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+		{src: "5f42010243030405ff", want: "AQIDBAU="},
+		{src: "7f657374726561646d696e67ff", want: "streaming"},
+		{src: "9f018202039f0405ffff", want: []any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}}},
+		{src: "bf61610161629f0203ffff", want: map[string]any{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+		{src: "d9d9f763666f6f", want: "foo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.src)
+			got := ParseCBOR(data)
+			if got.err != nil {
+				t.Fatal(got.err)
+			}
+			if !reflect.DeepEqual(got.value, tt.want) {
+				t.Errorf("\ngot  %#v\nwant %#v", got.value, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCBORError(t *testing.T) {
+
+	tests := []struct {
+		name string
+		src  string
+		opts []CBOROption
+		want string
+	}{
+		{name: "empty", src: "", want: "offset 0: unexpected end of CBOR input"},
+		{name: "truncated", src: "a1616182", want: "offset 3: unexpected end of CBOR input at $['a']"},
+		{name: "huge length", src: "9bffffffffffffffff", want: "offset 0: unexpected end of CBOR input"},
+		{name: "trailing data", src: "0101", want: "offset 1: unexpected data after top-level CBOR item"},
+		{name: "invalid utf-8", src: "820062ff00", want: "offset 2: invalid UTF-8 in text string at $[1]"},
+		{name: "map key", src: "a1f500", want: "offset 1: unsupported map key type bool"},
+		{name: "nan", src: "81f97e00", want: "offset 1: NaN cannot be represented in JSON at $[0]"},
+		{name: "break", src: "81ff", want: "offset 1: unexpected break at $[0]"},
+		{name: "reserved", src: "1c", want: "offset 0: invalid additional information 28"},
+		{name: "chunk", src: "5f6161ff", want: "offset 1: invalid chunk in indefinite-length string"},
+		{name: "max depth", src: "818180", opts: []CBOROption{CBORMaxDepth(1)}, want: "offset 2: exceeded max depth at $[0][0]"},
+		{name: "max length", src: "83010203", opts: []CBOROption{CBORMaxLength(2)}, want: "offset 0: length 3 exceeds the maximum of 2"},
+		{name: "max length of indefinite array", src: "9f01020304ff", opts: []CBOROption{CBORMaxLength(2)}, want: "offset 0: length 3 exceeds the maximum of 2"},
+		{name: "max length of indefinite map", src: "bf616101616202616303ff", opts: []CBOROption{CBORMaxLength(2)}, want: "offset 0: length 3 exceeds the maximum of 2"},
+		{name: "max length of indefinite string", src: "5f420102420304ff", opts: []CBOROption{CBORMaxLength(3)}, want: "offset 0: length 4 exceeds the maximum of 3"},
+		{name: "bignum of text", src: "c26131", want: "offset 0: bignum content must be a byte string"},
+		{name: "bignum of tagged bytes", src: "c2d54101", want: "offset 0: bignum content must be a byte string"},
+		{name: "negative bignum of tagged bytes", src: "c3d54101", want: "offset 0: bignum content must be a byte string"},
+		{name: "truncated bignum", src: "c2", want: "offset 1: unexpected end of CBOR input"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.src)
+			err := ParseCBOR(data, tt.opts...).Error()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("\ngot  %v\nwant %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCBORByteStrings(t *testing.T) {
+	data, _ := hex.DecodeString("4401020304")
+	node := ParseCBOR(data, CBORByteStrings(func(b []byte) any {
+		elems := []any{}
+		for _, c := range b {
+			elems = append(elems, int(c))
+		}
+		return elems
+	}))
+
+	got, err := node.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "[1,2,3,4]" {
+		t.Errorf("\ngot  %s\nwant [1,2,3,4]", got)
+	}
+}
+
+func TestCBORByteStringsBignum(t *testing.T) {
+	// the content of a bignum is not a JSON value, so the mapping does not apply to it
+	data, _ := hex.DecodeString("c3420100")
+	node := ParseCBOR(data, CBORByteStrings(func(b []byte) any { return "mapped" }))
+	if node.err != nil || node.value != json.Number("-257") {
+		t.Errorf("\ngot  %#v, %v\nwant -257", node.value, node.err)
+	}
+}
+
+func FuzzParseCBOR(f *testing.F) {
+	for _, src := range []string{
+		"00", "1bffffffffffffffff", "c249010000000000000000", "c3d54101", "c2d54101",
+		"9f0102ff", "bf6161f5ff", "5f4101ff", "7f6161ff", "c48221196ab3", "d74401020304", "fb3ff199999999999a",
+	} {
+		data, _ := hex.DecodeString(src)
+		f.Add(data)
+	}
+
+	// decoding untrusted input must never panic. Marshalling may still fail, for example for
+	// a decimal fraction whose exponent is out of the range of float64.
+	f.Fuzz(func(t *testing.T, data []byte) {
+		node := ParseCBOR(data, CBORMaxLength(1000))
+		if node.err == nil {
+			_, _ = node.MarshalCBOR()
+		}
+	})
+}
+
+func TestMarshalCBOR(t *testing.T) {
+
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{src: `0`, want: "00"},
+		{src: `1000000`, want: "1a000f4240"},
+		{src: `-1000`, want: "3903e7"},
+		{src: `18446744073709551615`, want: "1bffffffffffffffff"},
+		{src: `18446744073709551616`, want: "c249010000000000000000"},
+		{src: `-18446744073709551617`, want: "c349010000000000000000"},
+		{src: `1.5`, want: "f93e00"},
+		{src: `-0.0`, want: "f98000"},
+		{src: `65504.0`, want: "f97bff"},
+		{src: `5.960464477539063e-8`, want: "f90001"},
+		{src: `100000.5`, want: "fa47c35040"},
+		{src: `1.1`, want: "fb3ff199999999999a"},
+		{src: `null`, want: "f6"},
+		{src: `"IETF"`, want: "6449455446"},
+		{src: `{"b": [true, false], "aa": 1, "a": {}}`, want: "a36161a0616282f5f462616101"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			got, err := Parse([]byte(tt.src), UseNumber()).MarshalCBOR()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("\ngot  %x\nwant %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (&Node{value: math.Inf(1), path: jsonpath{}}).MarshalCBOR(); err == nil {
+		t.Errorf("MarshalCBOR accepted +Inf")
+	}
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	src := `{"name": "web", "ports": [80, 443, -1], "ratio": 0.25, "big": 12345678901234567890123, "nested": [{"a": null, "b": [true]}]}`
+
+	node := Parse([]byte(src), UseNumber())
+	data, err := node.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	equal, err := Equal(mustMarshal(t, ParseCBOR(data)), []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal {
+		t.Errorf("round trip changed the value: %x", data)
+	}
+}
+
+func mustMarshal(t *testing.T, n *Node) []byte {
+	t.Helper()
+	data, err := n.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,220 @@
+package jsond
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// converter converts between JSON values and values of a registered type.
+// Either function may be nil, if the conversion is only registered in one direction.
+type converter struct {
+	decode func(n *Node, v reflect.Value) error
+	encode func(v reflect.Value) (any, error)
+}
+
+var converters sync.Map // map[reflect.Type]*converter
+
+// RegisterConverter registers functions that convert between JSON values and values of type T.
+// Unmarshal and UnmarshalNode call decode for values of type T, and Set and Builder.Set call encode,
+// taking precedence over the methods of T such as UnmarshalJSON and MarshalJSON.
+// Pointers to T are converted with the same functions, and null is decoded into them as nil without calling decode.
+//
+// The value returned by encode is converted like any other value passed to Set, so it must not be of type T.
+// Either function may be nil, to convert in only one direction. Registering a type again replaces its converter.
+// RegisterConverter is meant to be called during initialization, and is safe for concurrent use.
+//
+// Converters are registered for time.Time, net.IP and big.Int by default, which write the same JSON as encoding/json.
+// The UnmarshalOptions DurationStrings, URLStrings and BigFloatNumbers read time.Duration, url.URL and big.Float
+// from other JSON values than encoding/json does, for a single call.
+func RegisterConverter[T any](decode func(*Node) (T, error), encode func(T) (any, error)) {
+	converters.Store(reflect.TypeOf((*T)(nil)).Elem(), newConverter(decode, encode))
+}
+
+// newConverter returns a converter that calls decode and encode, either of which may be nil.
+func newConverter[T any](decode func(*Node) (T, error), encode func(T) (any, error)) *converter {
+	c := &converter{}
+	if decode != nil {
+		c.decode = func(n *Node, v reflect.Value) error {
+			t, err := decode(n)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(&t).Elem())
+			return nil
+		}
+	}
+	if encode != nil {
+		c.encode = func(v reflect.Value) (any, error) {
+			return encode(v.Interface().(T))
+		}
+	}
+	return c
+}
+
+// lookupConverter returns the converter registered for t, if any.
+func lookupConverter(t reflect.Type) *converter {
+	c, ok := converters.Load(t)
+	if !ok {
+		return nil
+	}
+	return c.(*converter)
+}
+
+// RegisterTimeLayouts replaces the converter for time.Time with one that parses strings with the given layouts,
+// trying them in order, and formats times with the first one.
+// The default converter uses time.RFC3339Nano.
+func RegisterTimeLayouts(layouts ...string) {
+	if len(layouts) == 0 {
+		panic("invalid time layouts. no layout is given")
+	}
+
+	RegisterConverter(
+		func(n *Node) (time.Time, error) {
+			s, err := UnmarshalNode[string](n)
+			if err != nil {
+				return time.Time{}, err
+			}
+
+			var firstErr error
+			for _, layout := range layouts {
+				t, err := time.Parse(layout, s)
+				if err == nil {
+					return t, nil
+				}
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+			return time.Time{}, firstErr
+		},
+		func(t time.Time) (any, error) {
+			return t.Format(layouts[0]), nil
+		},
+	)
+}
+
+func init() {
+	RegisterTimeLayouts(time.RFC3339Nano)
+
+	RegisterConverter(
+		func(n *Node) (net.IP, error) {
+			s, err := UnmarshalNode[string](n)
+			if err != nil {
+				return nil, err
+			}
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", s)
+			}
+			return ip, nil
+		},
+		func(ip net.IP) (any, error) {
+			if len(ip) == 0 {
+				return "", nil
+			}
+			return ip.String(), nil
+		},
+	)
+
+	// big integers are read from JSON numbers or from strings, and written as JSON numbers without loss of precision
+	RegisterConverter(
+		func(n *Node) (big.Int, error) {
+			var i big.Int
+			literal, err := bigNumberLiteral(n)
+			if err != nil {
+				return i, err
+			}
+			if _, ok := i.SetString(literal, 10); !ok {
+				r, ok := new(big.Rat).SetString(literal)
+				if !ok || !r.IsInt() {
+					return i, fmt.Errorf("invalid integer %q", literal)
+				}
+				i.Set(r.Num())
+			}
+			return i, nil
+		},
+		func(i big.Int) (any, error) {
+			return json.Number(i.String()), nil
+		},
+	)
+}
+
+// UnmarshalOption configures how Unmarshal and UnmarshalNode decode a Node.
+type UnmarshalOption func(*unmarshalConfig)
+
+type unmarshalConfig struct {
+	converters map[reflect.Type]*converter // converters that take precedence over the registered ones
+}
+
+// withDecoder returns an UnmarshalOption that decodes values of type T with decode, for that call only.
+func withDecoder[T any](decode func(*Node) (T, error)) UnmarshalOption {
+	c := newConverter(decode, nil)
+	return func(cfg *unmarshalConfig) {
+		if cfg.converters == nil {
+			cfg.converters = map[reflect.Type]*converter{}
+		}
+		cfg.converters[reflect.TypeOf((*T)(nil)).Elem()] = c
+	}
+}
+
+// DurationStrings makes Unmarshal read time.Duration from strings such as "1m30s", as well as from numbers of nanoseconds.
+// By default, durations are read from numbers of nanoseconds only, as encoding/json does.
+func DurationStrings() UnmarshalOption {
+	return withDecoder(func(n *Node) (time.Duration, error) {
+		if s, ok := n.value.(string); ok {
+			return time.ParseDuration(s)
+		}
+		ns, err := UnmarshalNode[int64](n)
+		return time.Duration(ns), err
+	})
+}
+
+// URLStrings makes Unmarshal read url.URL from strings such as "https://example.com/".
+// By default, url.URL is read from an object of its fields, as encoding/json does.
+func URLStrings() UnmarshalOption {
+	return withDecoder(func(n *Node) (url.URL, error) {
+		s, err := UnmarshalNode[string](n)
+		if err != nil {
+			return url.URL{}, err
+		}
+		u, err := url.Parse(s)
+		if err != nil {
+			return url.URL{}, err
+		}
+		return *u, nil
+	})
+}
+
+// BigFloatNumbers makes Unmarshal read big.Float from JSON numbers without loss of precision, as well as from strings.
+// By default, big.Float is read from strings only, through its UnmarshalText method as encoding/json does.
+func BigFloatNumbers() UnmarshalOption {
+	return withDecoder(func(n *Node) (big.Float, error) {
+		var f big.Float
+		literal, err := bigNumberLiteral(n)
+		if err != nil {
+			return f, err
+		}
+		if _, ok := f.SetString(literal); !ok {
+			return f, fmt.Errorf("invalid number %q", literal)
+		}
+		return f, nil
+	})
+}
+
+// bigNumberLiteral returns the literal of the JSON number, or the content of the JSON string, of the Node.
+func bigNumberLiteral(n *Node) (string, error) {
+	switch t := n.value.(type) {
+	case string:
+		return t, nil
+	case float64, json.Number, int64, uint64:
+		return numberLiteral(t), nil
+	default:
+		return "", fmt.Errorf("cannot convert %s to a number", getTypeString(n.value))
+	}
+}
@@ -0,0 +1,223 @@
+package jsond
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type converterConfig struct {
+	Started  time.Time     `json:"started"`
+	Timeout  time.Duration `json:"timeout"`
+	Retry    time.Duration `json:"retry"`
+	Addr     net.IP        `json:"addr"`
+	Endpoint *url.URL      `json:"endpoint"`
+	Supply   *big.Int      `json:"supply"`
+	Price    *big.Float    `json:"price"`
+}
+
+func TestBuiltinConverters(t *testing.T) {
+	supply, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	price, _ := new(big.Float).SetString("0.1")
+	v := converterConfig{
+		Started:  time.Date(2024, 1, 2, 3, 4, 5, 500000000, time.UTC),
+		Timeout:  90 * time.Second,
+		Retry:    500,
+		Addr:     net.IPv4(10, 0, 0, 1),
+		Endpoint: &url.URL{Scheme: "https", Host: "example.com", Path: "/api", RawQuery: "x=1"},
+		Supply:   supply,
+		Price:    price,
+	}
+
+	// the default converters write the same JSON as encoding/json
+	want, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := Parse([]byte(`{}`)).Set(v)
+	if equal, _ := Equal(want, must(node.Marshal())); !equal {
+		t.Errorf("\ngot  %s\nwant %s", must(node.Marshal()), want)
+	}
+
+	var got converterConfig
+	if err := Parse(want, UseNumber()).Unmarshal(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("\ngot  %+v\nwant %+v", got, v)
+	}
+}
+
+func TestUnmarshalOptions(t *testing.T) {
+	src := []byte(`{
+		"started": "2024-01-02T03:04:05.5Z",
+		"timeout": "1m30s",
+		"retry": 500,
+		"addr": "10.0.0.1",
+		"endpoint": "https://example.com/api?x=1",
+		"supply": 123456789012345678901234567890,
+		"price": 0.1
+	}`)
+
+	var got converterConfig
+	if err := Parse(src, UseNumber()).Unmarshal(&got, DurationStrings(), URLStrings(), BigFloatNumbers()); err != nil {
+		t.Fatal(err)
+	}
+
+	supply, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	price, _ := new(big.Float).SetString("0.1")
+	want := converterConfig{
+		Started:  time.Date(2024, 1, 2, 3, 4, 5, 500000000, time.UTC),
+		Timeout:  90 * time.Second,
+		Retry:    500,
+		Addr:     net.IPv4(10, 0, 0, 1),
+		Endpoint: &url.URL{Scheme: "https", Host: "example.com", Path: "/api", RawQuery: "x=1"},
+		Supply:   supply,
+		Price:    price,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %+v\nwant %+v", got, want)
+	}
+
+	// the options only apply to the call they are passed to
+	timeout := Parse(src).Get("timeout")
+	if _, err := UnmarshalNode[time.Duration](timeout, DurationStrings()); err != nil {
+		t.Error(err)
+	}
+	wantErr := "json: cannot unmarshal string into Go value of type time.Duration at $['timeout']"
+	if _, err := UnmarshalNode[time.Duration](timeout); err == nil || err.Error() != wantErr {
+		t.Errorf("\ngot  %v\nwant %s", err, wantErr)
+	}
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestBuiltinConverterErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		f    func(*Node) error
+		want string
+	}{
+		{
+			name: "duration",
+			f:    func(n *Node) error { _, err := UnmarshalNode[time.Duration](n.Get("v"), DurationStrings()); return err },
+			want: `time: unknown unit "x" in duration "5x" at $['v']`,
+		},
+		{
+			name: "IP",
+			f:    func(n *Node) error { _, err := UnmarshalNode[net.IP](n.Get("v")); return err },
+			want: `invalid IP address "5x" at $['v']`,
+		},
+		{
+			name: "time from a number",
+			f: func(n *Node) error {
+				var v struct{ T []time.Time }
+				return Parse([]byte(`{"T": [1]}`)).Unmarshal(&v)
+			},
+			want: "json: cannot unmarshal number into Go value of type string at $['T'][0]",
+		},
+		{
+			name: "big integer",
+			f:    func(n *Node) error { _, err := UnmarshalNode[*big.Int](Parse([]byte(`1.5`))); return err },
+			want: `invalid integer "1.5"`,
+		},
+	}
+
+	root := Parse([]byte(`{"v": "5x"}`))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.f(root)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("\ngot  %v\nwant %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterTimeLayouts(t *testing.T) {
+	defer RegisterTimeLayouts(time.RFC3339Nano)
+	RegisterTimeLayouts(time.DateOnly, time.RFC3339)
+
+	var got []time.Time
+	if err := Parse([]byte(`["2024-01-02", "2024-01-02T03:04:05Z"]`)).Unmarshal(&got); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := Parse([]byte(`{}`)).Set(got).Marshal()
+	if want := `["2024-01-02","2024-01-02"]`; string(data) != want {
+		t.Errorf("\ngot  %s\nwant %s", data, want)
+	}
+}
+
+// converterLevel has a converter registered in the tests, which takes precedence over its UnmarshalText method.
+type converterLevel int
+
+func (l *converterLevel) UnmarshalText(text []byte) error {
+	return errors.New("UnmarshalText must not be called")
+}
+
+type converterSame struct{}
+
+func init() {
+	RegisterConverter(
+		func(n *Node) (converterLevel, error) {
+			s, err := UnmarshalNode[string](n)
+			if err != nil {
+				return 0, err
+			}
+			switch s {
+			case "low":
+				return 1, nil
+			case "high":
+				return 2, nil
+			}
+			return 0, fmt.Errorf("unknown level %q", s)
+		},
+		func(l converterLevel) (any, error) {
+			return strings.Repeat("!", int(l)), nil
+		},
+	)
+	RegisterConverter(nil, func(v converterSame) (any, error) { return v, nil })
+}
+
+func TestRegisterConverter(t *testing.T) {
+	var got struct {
+		Level  converterLevel   `json:"level"`
+		Ptr    *converterLevel  `json:"ptr"`
+		Null   *converterLevel  `json:"null"`
+		Levels []converterLevel `json:"levels"`
+	}
+	src := `{"level": "high", "ptr": "low", "null": null, "levels": ["low", "high"]}`
+	if err := Parse([]byte(src)).Unmarshal(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Level != 2 || got.Ptr == nil || *got.Ptr != 1 || got.Null != nil || !reflect.DeepEqual(got.Levels, []converterLevel{1, 2}) {
+		t.Errorf("\ngot  %+v", got)
+	}
+
+	data, _ := Parse([]byte(`{}`)).Set(got).Marshal()
+	if want := `{"level":"!!","levels":["!","!!"],"null":null,"ptr":"!"}`; string(data) != want {
+		t.Errorf("\ngot  %s\nwant %s", data, want)
+	}
+
+	want := `unknown level "medium" at $['levels'][1]`
+	if err := Parse([]byte(`{"levels": ["low", "medium"]}`)).Unmarshal(&got); err == nil || err.Error() != want {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+
+	want = "Go value [0]: converter for jsond.converterSame returned a value of the same type"
+	if err := Parse([]byte(`{}`)).Set([]converterSame{{}}).Error(); err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+}
@@ -0,0 +1,604 @@
+package jsond
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	jsonNumberType      = reflect.TypeOf(json.Number(""))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decoder decodes a jsonvalue into a Go value through reflection, following the rules of json.Unmarshal,
+// so that Node.Unmarshal does not need to marshal the value and parse it again.
+// Like json.Unmarshal, it keeps decoding after a type error, and reports the first one.
+type decoder struct {
+	rootPath jsonpath     // path of the decoded Node
+	rootType reflect.Type // type of the value decoded into, for error messages
+	config   unmarshalConfig
+	err      error
+}
+
+// decodeValue decodes v into the value pointed to by ptr.
+func decodeValue(path jsonpath, v jsonvalue, ptr any, config unmarshalConfig) error {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &NodeError{
+			code: codeUnmarshalError,
+			path: path,
+			err:  &json.InvalidUnmarshalError{Type: reflect.TypeOf(ptr)},
+		}
+	}
+
+	d := &decoder{
+		rootPath: path,
+		rootType: rv.Type().Elem(),
+		config:   config,
+	}
+	d.value(path, v, rv)
+	return d.err
+}
+
+// saveError records err at path, if it is the first error.
+func (d *decoder) saveError(path jsonpath, err error) {
+	if d.err != nil {
+		return
+	}
+	d.err = &NodeError{
+		code: codeUnmarshalError,
+		path: path,
+		err:  err,
+	}
+}
+
+// typeError records a json.UnmarshalTypeError for the JSON value described by value at path.
+// Like json.Unmarshal, the field of the error is the dot-separated path from the decoded value.
+func (d *decoder) typeError(path jsonpath, value string, typ reflect.Type) {
+	fields := make([]string, 0, len(path)-len(d.rootPath))
+	for _, prop := range path[len(d.rootPath):] {
+		fields = append(fields, fmt.Sprint(prop))
+	}
+
+	typeErr := &json.UnmarshalTypeError{
+		Value: value,
+		Type:  typ,
+		Field: strings.Join(fields, "."),
+	}
+	root := d.rootType
+	for root.Kind() == reflect.Pointer {
+		root = root.Elem()
+	}
+	if typeErr.Field != "" && root.Kind() == reflect.Struct {
+		typeErr.Struct = root.Name()
+	}
+	d.saveError(path, typeErr)
+}
+
+// value decodes v into rv.
+func (d *decoder) value(path jsonpath, v jsonvalue, rv reflect.Value) {
+	if v != nil && d.converted(path, v, rv) {
+		return
+	}
+
+	u, ut, pv := indirect(rv, v == nil)
+	if u != nil {
+		d.unmarshaler(path, v, u)
+		return
+	}
+	if ut != nil {
+		s, ok := v.(string)
+		if !ok {
+			d.typeError(path, jsonTypeName(v), rv.Type())
+			return
+		}
+		if err := ut.UnmarshalText([]byte(s)); err != nil {
+			d.saveError(path, err)
+		}
+		return
+	}
+
+	switch t := v.(type) {
+	case nil:
+		switch pv.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			pv.SetZero()
+		}
+	case bool:
+		d.bool(path, t, pv)
+	case string:
+		d.string(path, t, pv)
+	case []any:
+		d.array(path, t, pv)
+	case map[string]any:
+		d.object(path, t, pv)
+	default:
+		d.number(path, t, pv)
+	}
+}
+
+// converted decodes v with the converter registered for the type of rv, or of the value rv points to,
+// allocating the pointers on the way. It returns false if there is no such converter.
+func (d *decoder) converted(path jsonpath, v jsonvalue, rv reflect.Value) bool {
+	t := rv.Type()
+	depth := 0
+	c := d.lookupConverter(t)
+	for c == nil || c.decode == nil {
+		if t.Kind() != reflect.Pointer {
+			return false
+		}
+		t = t.Elem()
+		depth++
+		c = d.lookupConverter(t)
+	}
+
+	for ; depth > 0; depth-- {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+
+	err := c.decode(&Node{value: v, path: path}, rv)
+	if nodeErr, ok := err.(*NodeError); ok && d.err == nil {
+		// the Node passed to the converter has the full path, so its errors already have the right path
+		d.err = nodeErr
+	} else if err != nil {
+		d.saveError(path, err)
+	}
+	return true
+}
+
+// lookupConverter returns the converter given by the UnmarshalOptions for t, or else the one registered for t, if any.
+func (d *decoder) lookupConverter(t reflect.Type) *converter {
+	if c, ok := d.config.converters[t]; ok {
+		return c
+	}
+	return lookupConverter(t)
+}
+
+// unmarshaler decodes v with its json.Unmarshaler. A Node receives v as is, without marshalling it.
+func (d *decoder) unmarshaler(path jsonpath, v jsonvalue, u json.Unmarshaler) {
+	if node, ok := u.(*Node); ok {
+		*node = Node{
+			parent: nil,
+			value:  v,
+			path:   jsonpath{},
+			err:    nil,
+		}
+		return
+	}
+
+	data, err := marshal(path, v)
+	if err != nil {
+		d.saveError(path, err)
+		return
+	}
+	if err := u.UnmarshalJSON(data); err != nil {
+		d.saveError(path, err)
+	}
+}
+
+func (d *decoder) bool(path jsonpath, b bool, pv reflect.Value) {
+	switch {
+	case pv.Kind() == reflect.Bool:
+		pv.SetBool(b)
+	case pv.Kind() == reflect.Interface && pv.NumMethod() == 0:
+		pv.Set(reflect.ValueOf(b))
+	default:
+		d.typeError(path, "bool", pv.Type())
+	}
+}
+
+func (d *decoder) string(path jsonpath, s string, pv reflect.Value) {
+	switch {
+	case pv.Kind() == reflect.Slice && pv.Type().Elem().Kind() == reflect.Uint8:
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			d.saveError(path, err)
+			return
+		}
+		pv.SetBytes(b)
+	case pv.Kind() == reflect.String:
+		if pv.Type() == jsonNumberType && !isValidNumber(json.Number(s)) {
+			d.saveError(path, fmt.Errorf("json: invalid number literal, trying to unmarshal %q into Number", s))
+			return
+		}
+		pv.SetString(s)
+	case pv.Kind() == reflect.Interface && pv.NumMethod() == 0:
+		pv.Set(reflect.ValueOf(s))
+	default:
+		d.typeError(path, "string", pv.Type())
+	}
+}
+
+func (d *decoder) number(path jsonpath, v jsonvalue, pv reflect.Value) {
+	switch pv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := numberInt64(v)
+		if !ok || pv.OverflowInt(i) {
+			d.typeError(path, "number "+numberLiteral(v), pv.Type())
+			return
+		}
+		pv.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, ok := numberUint64(v)
+		if !ok || pv.OverflowUint(u) {
+			d.typeError(path, "number "+numberLiteral(v), pv.Type())
+			return
+		}
+		pv.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := numberFloat64(v)
+		if err != nil || pv.OverflowFloat(f) {
+			d.typeError(path, "number "+numberLiteral(v), pv.Type())
+			return
+		}
+		pv.SetFloat(f)
+
+	case reflect.String:
+		if pv.Type() != jsonNumberType {
+			d.typeError(path, "number", pv.Type())
+			return
+		}
+		pv.SetString(numberLiteral(v))
+
+	case reflect.Interface:
+		if pv.NumMethod() != 0 {
+			d.typeError(path, "number", pv.Type())
+			return
+		}
+		f, err := numberFloat64(v)
+		if err != nil {
+			d.typeError(path, "number "+numberLiteral(v), reflect.TypeOf(0.0))
+			return
+		}
+		pv.Set(reflect.ValueOf(f))
+
+	default:
+		d.typeError(path, "number", pv.Type())
+	}
+}
+
+func (d *decoder) array(path jsonpath, array []any, pv reflect.Value) {
+	switch pv.Kind() {
+	case reflect.Interface:
+		if pv.NumMethod() != 0 {
+			d.typeError(path, "array", pv.Type())
+			return
+		}
+		pv.Set(reflect.ValueOf(d.interfaceValue(path, array)))
+
+	case reflect.Slice:
+		if pv.Cap() < len(array) {
+			// existing elements are kept, so that they are decoded into as json.Unmarshal does
+			grown := reflect.MakeSlice(pv.Type(), len(array), len(array))
+			reflect.Copy(grown, pv)
+			pv.Set(grown)
+		} else {
+			pv.SetLen(len(array))
+		}
+		if pv.IsNil() {
+			pv.Set(reflect.MakeSlice(pv.Type(), 0, 0))
+		}
+		for i, elem := range array {
+			d.value(path.append(arrayIndex(i)), elem, pv.Index(i))
+		}
+
+	case reflect.Array:
+		for i := 0; i < pv.Len(); i++ {
+			if i < len(array) {
+				d.value(path.append(arrayIndex(i)), array[i], pv.Index(i))
+			} else {
+				pv.Index(i).SetZero()
+			}
+		}
+
+	default:
+		d.typeError(path, "array", pv.Type())
+	}
+}
+
+func (d *decoder) object(path jsonpath, object map[string]any, pv reflect.Value) {
+	// members are decoded in key order, so that the reported error does not depend on map iteration order
+	keys := make([]string, 0, len(object))
+	for k := range object {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	switch pv.Kind() {
+	case reflect.Interface:
+		if pv.NumMethod() != 0 {
+			d.typeError(path, "object", pv.Type())
+			return
+		}
+		pv.Set(reflect.ValueOf(d.interfaceValue(path, object)))
+
+	case reflect.Map:
+		d.mapValue(path, object, keys, pv)
+
+	case reflect.Struct:
+		fields := cachedTypeFields(pv.Type())
+		for _, k := range keys {
+			f := fields.lookup(k)
+			if f == nil {
+				continue
+			}
+			memberPath := path.append(objectKey(k))
+			subv, ok := d.fieldByIndex(memberPath, pv, f.index)
+			if !ok {
+				continue
+			}
+
+			if f.quoted {
+				d.quoted(memberPath, object[k], subv)
+			} else {
+				d.value(memberPath, object[k], subv)
+			}
+		}
+
+	default:
+		d.typeError(path, "object", pv.Type())
+	}
+}
+
+func (d *decoder) mapValue(path jsonpath, object map[string]any, keys []string, pv reflect.Value) {
+	kt := pv.Type().Key()
+	textKey := reflect.PointerTo(kt).Implements(textUnmarshalerType)
+	switch {
+	case textKey, kt.Kind() == reflect.String:
+	case kt.Kind() >= reflect.Int && kt.Kind() <= reflect.Uintptr:
+	default:
+		d.typeError(path, "object", pv.Type())
+		return
+	}
+
+	if pv.IsNil() {
+		pv.Set(reflect.MakeMap(pv.Type()))
+	}
+
+	et := pv.Type().Elem()
+	for _, k := range keys {
+		memberPath := path.append(objectKey(k))
+
+		elem := reflect.New(et).Elem()
+		d.value(memberPath, object[k], elem)
+
+		var kv reflect.Value
+		switch {
+		case textKey:
+			kp := reflect.New(kt)
+			if err := kp.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+				d.saveError(memberPath, err)
+				continue
+			}
+			kv = kp.Elem()
+		case kt.Kind() == reflect.String:
+			kv = reflect.ValueOf(k).Convert(kt)
+		case kt.Kind() >= reflect.Int && kt.Kind() <= reflect.Int64:
+			n, err := strconv.ParseInt(k, 10, 64)
+			if err != nil || reflect.Zero(kt).OverflowInt(n) {
+				d.typeError(memberPath, "number "+k, kt)
+				continue
+			}
+			kv = reflect.ValueOf(n).Convert(kt)
+		default:
+			n, err := strconv.ParseUint(k, 10, 64)
+			if err != nil || reflect.Zero(kt).OverflowUint(n) {
+				d.typeError(memberPath, "number "+k, kt)
+				continue
+			}
+			kv = reflect.ValueOf(n).Convert(kt)
+		}
+		pv.SetMapIndex(kv, elem)
+	}
+}
+
+// fieldByIndex returns the struct field with the index sequence, allocating the embedded pointers on the way.
+func (d *decoder) fieldByIndex(path jsonpath, v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					d.saveError(path, fmt.Errorf("json: cannot set embedded pointer to unexported struct: %v", v.Type().Elem()))
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// quoted decodes a field with the ",string" option, whose value is encoded in a JSON string.
+func (d *decoder) quoted(path jsonpath, v jsonvalue, pv reflect.Value) {
+	switch t := v.(type) {
+	case nil:
+		d.value(path, nil, pv)
+	case string:
+		inner := Parse([]byte(t), UseNumber())
+		switch inner.value.(type) {
+		case []any, map[string]any:
+		default:
+			if inner.err == nil {
+				d.value(path, inner.value, pv)
+				return
+			}
+		}
+		d.saveError(path, fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal %q into %v", t, pv.Type()))
+	default:
+		d.typeError(path, jsonTypeName(v), pv.Type())
+	}
+}
+
+// interfaceValue returns v as json.Unmarshal decodes it into an empty interface:
+// numbers are float64, and containers are copied so that the Node's value is never shared.
+func (d *decoder) interfaceValue(path jsonpath, v jsonvalue) any {
+	switch t := v.(type) {
+	case []any:
+		array := make([]any, len(t))
+		for i, elem := range t {
+			array[i] = d.interfaceValue(path.append(arrayIndex(i)), elem)
+		}
+		return array
+	case map[string]any:
+		object := make(map[string]any, len(t))
+		for k, elem := range t {
+			object[k] = d.interfaceValue(path.append(objectKey(k)), elem)
+		}
+		return object
+	case nil, bool, string:
+		return v
+	default:
+		f, err := numberFloat64(v)
+		if err != nil {
+			d.typeError(path, "number "+numberLiteral(v), reflect.TypeOf(0.0))
+			return nil
+		}
+		return f
+	}
+}
+
+// indirect walks down v, allocating pointers as needed, until it reaches a non-pointer value.
+// If it finds a json.Unmarshaler or an encoding.TextUnmarshaler on the way, it returns it instead.
+// If decodingNull is true, it stops at the last settable pointer, so that it can be set to nil.
+// It is a port of the function of the same name in encoding/json.
+func indirect(v reflect.Value, decodingNull bool) (json.Unmarshaler, encoding.TextUnmarshaler, reflect.Value) {
+	v0 := v
+	haveAddr := false
+
+	// a named value that is addressable may have methods on its pointer type
+	if v.Kind() != reflect.Pointer && v.Type().Name() != "" && v.CanAddr() {
+		haveAddr = true
+		v = v.Addr()
+	}
+	for {
+		// an interface holding a non-nil pointer is decoded into the pointed value
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			e := v.Elem()
+			if e.Kind() == reflect.Pointer && !e.IsNil() && (!decodingNull || e.Elem().Kind() == reflect.Pointer) {
+				haveAddr = false
+				v = e
+				continue
+			}
+		}
+
+		if v.Kind() != reflect.Pointer {
+			break
+		}
+		if decodingNull && v.CanSet() {
+			break
+		}
+
+		// a pointer to an interface holding the pointer itself would loop forever
+		if v.Elem().Kind() == reflect.Interface && v.Elem().Elem().Equal(v) {
+			v = v.Elem()
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if v.Type().NumMethod() > 0 && v.CanInterface() {
+			if u, ok := v.Interface().(json.Unmarshaler); ok {
+				return u, nil, reflect.Value{}
+			}
+			if !decodingNull {
+				if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+					return nil, u, reflect.Value{}
+				}
+			}
+		}
+
+		if haveAddr {
+			v = v0
+			haveAddr = false
+		} else {
+			v = v.Elem()
+		}
+	}
+	return nil, nil, v
+}
+
+// jsonTypeName returns the name of the JSON type of v, as used in json.UnmarshalTypeError.
+func jsonTypeName(v jsonvalue) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return "number"
+	}
+}
+
+// numberLiteral returns the JSON number v as it is written in JSON.
+func numberLiteral(v jsonvalue) string {
+	switch t := v.(type) {
+	case json.Number:
+		return string(t)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case uint64:
+		return strconv.FormatUint(t, 10)
+	default:
+		data, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(data)
+	}
+}
+
+// numberInt64 returns the JSON number v as an int64. It returns false if v is not an integer within range.
+func numberInt64(v jsonvalue) (int64, bool) {
+	switch t := v.(type) {
+	case int64:
+		return t, true
+	case uint64:
+		return int64(t), t <= math.MaxInt64
+	case float64:
+		if t != math.Trunc(t) || t < math.MinInt64 || t >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(t), true
+	default:
+		i, err := strconv.ParseInt(numberLiteral(v), 10, 64)
+		return i, err == nil
+	}
+}
+
+// numberUint64 returns the JSON number v as a uint64. It returns false if v is not a non-negative integer within range.
+func numberUint64(v jsonvalue) (uint64, bool) {
+	switch t := v.(type) {
+	case int64:
+		return uint64(t), t >= 0
+	case uint64:
+		return t, true
+	case float64:
+		if t != math.Trunc(t) || t < 0 || t >= math.MaxUint64 {
+			return 0, false
+		}
+		return uint64(t), true
+	default:
+		u, err := strconv.ParseUint(numberLiteral(v), 10, 64)
+		return u, err == nil
+	}
+}
@@ -0,0 +1,249 @@
+package jsond
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type decodeEmbedded struct {
+	ID      int    `json:"id"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type DecodeOther struct {
+	Comment string `json:"comment"`
+	Extra   bool
+}
+
+type decodeHidden struct {
+	Hidden int `json:"hidden"`
+}
+
+type decodeLevel int
+
+func (l *decodeLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+type decodeVersion struct {
+	Major, Minor int
+}
+
+func (v *decodeVersion) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if _, err := fmt.Sscanf(s, "%d.%d", &v.Major, &v.Minor); err != nil {
+		return fmt.Errorf("invalid version %q", s)
+	}
+	return nil
+}
+
+type decodeTarget struct {
+	decodeEmbedded
+	*DecodeOther
+
+	Name     string            `json:"name"`
+	Count    int64             `json:"count,string"`
+	Ratio    float32           `json:"ratio"`
+	Tags     []string          `json:"tags"`
+	Pair     [2]int            `json:"pair"`
+	Labels   map[string]string `json:"labels"`
+	ByID     map[int]bool      `json:"by_id"`
+	Level    decodeLevel       `json:"level"`
+	Version  decodeVersion     `json:"version"`
+	Raw      json.RawMessage   `json:"raw"`
+	Num      json.Number       `json:"num"`
+	Any      any               `json:"any"`
+	Ptr      *int              `json:"ptr"`
+	Data     []byte            `json:"data"`
+	Skipped  string            `json:"-"`
+	Untagged uint8
+}
+
+func TestDecodeMatchesEncodingJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		new  func() any
+	}{
+		{
+			name: "struct",
+			src: `{
+				"id": 7, "comment": "hi", "Extra": true,
+				"name": "n", "count": "12", "ratio": 0.5,
+				"tags": ["a", "b"], "pair": [1, 2, 3],
+				"labels": {"k": "v"}, "by_id": {"1": true, "-2": false},
+				"level": "high", "version": "1.2",
+				"raw": {"x":[1,2]}, "num": 1.5, "any": {"a": [1, "x", null, true]},
+				"ptr": 3, "data": "aGVsbG8=", "Skipped": "x", "untagged": 9, "unknown": 1
+			}`,
+			new: func() any { return &decodeTarget{} },
+		},
+		{name: "case-insensitive names", src: `{"NAME": "n", "Id": 1}`, new: func() any { return &decodeTarget{} }},
+		{name: "null", src: `{"ptr": null, "tags": null, "name": null}`, new: func() any { return &decodeTarget{Name: "keep", Tags: []string{"x"}} }},
+		{name: "empty array", src: `[]`, new: func() any { return new([]int) }},
+		{name: "into interface", src: `[1, {"a": 2.5}]`, new: func() any { return new(any) }},
+		{name: "into map of structs", src: `{"a": {"id": 1}}`, new: func() any { return new(map[string]decodeEmbedded) }},
+		{name: "string into int", src: `{"id": "1"}`, new: func() any { return &decodeTarget{} }},
+		{name: "fraction into int", src: `{"id": 1.5}`, new: func() any { return &decodeTarget{} }},
+		{name: "overflow", src: `{"untagged": 300}`, new: func() any { return &decodeTarget{} }},
+		{name: "negative into uint", src: `[-1]`, new: func() any { return new([]uint) }},
+		{name: "nested", src: `{"tags": ["a", 1]}`, new: func() any { return &decodeTarget{} }},
+		{name: "nested in slice", src: `[{"id": 1}, {"id": true}]`, new: func() any { return new([]decodeEmbedded) }},
+		{name: "array into struct", src: `[1]`, new: func() any { return &decodeTarget{} }},
+		{name: "object into slice", src: `{}`, new: func() any { return new([]int) }},
+		{name: "invalid map key", src: `{"x": true}`, new: func() any { return new(map[int]bool) }},
+		{name: "text unmarshaler", src: `{"level": "nope"}`, new: func() any { return &decodeTarget{} }},
+		{name: "unmarshaler", src: `{"version": "latest"}`, new: func() any { return &decodeTarget{} }},
+		{name: "not a pointer", src: `1`, new: func() any { return 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.new()
+			wantErr := json.Unmarshal([]byte(tt.src), want)
+
+			got := tt.new()
+			gotErr := Parse([]byte(tt.src)).Unmarshal(got)
+
+			var nodeErr *NodeError
+			if errors.As(gotErr, &nodeErr) {
+				gotErr = nodeErr.err
+			}
+			if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+				t.Errorf("\ngot  %v\nwant %v", gotErr, wantErr)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("\ngot  %#v\nwant %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	// messages that json.Unmarshal words differently depending on the Go version
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "number into text unmarshaler",
+			src:  `{"level": 1}`,
+			want: "json: cannot unmarshal number into Go struct field .level of type jsond.decodeLevel at $['level']",
+		},
+		{
+			name: "invalid base64",
+			src:  `{"data": "!"}`,
+			want: "illegal base64 data at input byte 0 at $['data']",
+		},
+		{
+			name: "invalid use of string option",
+			src:  `{"count": "[1]"}`,
+			want: `json: invalid use of ,string struct tag, trying to unmarshal "[1]" into int64 at $['count']`,
+		},
+		{
+			name: "unexported embedded pointer",
+			src:  `{"hidden": 1}`,
+			want: "json: cannot set embedded pointer to unexported struct: jsond.decodeHidden at $['hidden']",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v struct {
+				decodeTarget
+				*decodeHidden
+			}
+			err := Parse([]byte(tt.src)).Unmarshal(&v)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("\ngot  %v\nwant %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeErrorPath(t *testing.T) {
+	root := Parse([]byte(`{"post": {"comments": [{"id": 1}, {"id": "2"}]}}`))
+
+	var post struct {
+		Comments []struct {
+			ID int `json:"id"`
+		} `json:"comments"`
+	}
+	err := root.Get("post").Unmarshal(&post)
+
+	want := "json: cannot unmarshal string into Go struct field .comments.1.id of type int at $['post']['comments'][1]['id']"
+	if err == nil || err.Error() != want {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Value != "string" {
+		t.Errorf("\ngot  %#v\nwant a json.UnmarshalTypeError", err)
+	}
+}
+
+func TestDecodeLargeNumbers(t *testing.T) {
+	tests := []struct {
+		name string
+		node *Node
+		want string
+	}{
+		{name: "json.Number", node: Parse([]byte(`[9007199254740993, 18446744073709551615]`), UseNumber())},
+		{name: "int64 and uint64", node: &Node{value: []any{int64(9007199254740993), uint64(18446744073709551615)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				I int64
+				U uint64
+			}
+			var pair []json.RawMessage
+			if err := tt.node.Unmarshal(&pair); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.node.Get(0).Unmarshal(&got.I); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.node.Get(1).Unmarshal(&got.U); err != nil {
+				t.Fatal(err)
+			}
+			if got.I != 9007199254740993 || got.U != 18446744073709551615 {
+				t.Errorf("\ngot  %d %d", got.I, got.U)
+			}
+			if s := strings.Join([]string{string(pair[0]), string(pair[1])}, ","); s != "9007199254740993,18446744073709551615" {
+				t.Errorf("\ngot  %s", s)
+			}
+		})
+	}
+}
+
+func TestDecodeDoesNotShareValues(t *testing.T) {
+	root := Parse([]byte(`{"a": [1, 2]}`))
+
+	var got map[string]any
+	if err := root.Unmarshal(&got); err != nil {
+		t.Fatal(err)
+	}
+	got["a"].([]any)[0] = "changed"
+
+	if b, _ := root.Marshal(); string(b) != `{"a":[1,2]}` {
+		t.Errorf("node was modified: %s", b)
+	}
+}
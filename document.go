@@ -0,0 +1,215 @@
+package jsond
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Document holds the current version of a JSON value shared between goroutines.
+// Since Nodes are immutable, a Node loaded from a Document is a consistent snapshot that can be read without locks,
+// while other goroutines replace the Document's root with updated versions.
+//
+// A Document must be created with NewDocument.
+type Document struct {
+	state atomic.Pointer[documentState]
+
+	mu            sync.Mutex
+	subscriptions []*subscription
+	notified      *documentState // the state subscribers were last notified of
+	notifying     bool           // a goroutine is calling subscribers
+}
+
+// documentState pairs a root with its version, so that both are swapped together.
+type documentState struct {
+	root    *Node
+	version uint64
+}
+
+// NewDocument returns a new Document whose root is root, at version 0.
+// It panics if root has an error.
+func NewDocument(root *Node) *Document {
+	if root.err != nil {
+		panic(fmt.Sprintf("invalid document root. err=%v", root.err))
+	}
+
+	state := &documentState{root: root, version: 0}
+	d := &Document{notified: state}
+	d.state.Store(state)
+	return d
+}
+
+// Load returns the current root of the Document.
+func (d *Document) Load() *Node {
+	return d.state.Load().root
+}
+
+// Version returns the current version of the Document, which is incremented each time its root is replaced.
+func (d *Document) Version() uint64 {
+	return d.state.Load().version
+}
+
+// Snapshot returns the current root of the Document together with its version.
+func (d *Document) Snapshot() (*Node, uint64) {
+	state := d.state.Load()
+	return state.root, state.version
+}
+
+// CompareAndSwap replaces the root of the Document with new if the current root is old, and reports whether it did.
+// Roots are compared by identity, so old must be a Node returned by Load, Snapshot or Update.
+// A new Node with an error is never stored, and CompareAndSwap returns false.
+func (d *Document) CompareAndSwap(old, new *Node) bool {
+	if new.err != nil {
+		return false
+	}
+
+	state := d.state.Load()
+	if state.root != old {
+		return false
+	}
+	if !d.state.CompareAndSwap(state, &documentState{root: new, version: state.version + 1}) {
+		return false
+	}
+
+	d.notify()
+	return true
+}
+
+// Update replaces the root of the Document with the result of f, and returns the new root.
+// If another goroutine replaces the root while f is running, f is called again with the newer root,
+// so f should have no side effects other than computing the new value.
+//
+// If f returns a Node with an error, the Document is left unchanged and Update returns the error.
+// If f returns the root it was given, the Document and its version are left unchanged.
+func (d *Document) Update(f func(*Node) *Node) (*Node, error) {
+	for {
+		state := d.state.Load()
+
+		updated := f(state.root)
+		if updated.err != nil {
+			return nil, updated.err
+		}
+		if updated == state.root {
+			return updated, nil
+		}
+
+		if d.state.CompareAndSwap(state, &documentState{root: updated, version: state.version + 1}) {
+			d.notify()
+			return updated, nil
+		}
+	}
+}
+
+type subscription struct {
+	props     []any
+	f         func(old, new *Node)
+	cancelled atomic.Bool
+}
+
+// Subscribe registers f to be called when the value at the given property path changes, and returns a function that cancels the subscription.
+// f receives the values at the path before and after the change; either may be undefined.
+//
+// Subscribers are called after the root is replaced, by the goroutine that replaced it, one at a time and in version order.
+// Changes made while subscribers are being called are reported together, as a single change from the last reported version.
+// Subscribers may update the Document themselves.
+// A panic in a subscriber propagates to the goroutine that replaced the root, and skips the other subscribers for that change.
+func (d *Document) Subscribe(f func(old, new *Node), props ...any) (cancel func()) {
+	for _, prop := range props {
+		if _, ok := prop.(Selector); ok {
+			continue
+		}
+		if _, err := getProperty(prop); err != nil {
+			panic(fmt.Sprintf("invalid property. prop=%v, type=%T", prop, prop))
+		}
+	}
+
+	sub := &subscription{props: props, f: f}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscriptions = append(d.subscriptions, sub)
+
+	return func() {
+		sub.cancelled.Store(true)
+
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		for i, s := range d.subscriptions {
+			if s == sub {
+				d.subscriptions = append(d.subscriptions[:i:i], d.subscriptions[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// notify calls the subscribers whose values changed since they were last notified.
+// If another goroutine is already calling subscribers, it reports the change instead.
+func (d *Document) notify() {
+	d.mu.Lock()
+	if d.notifying {
+		d.mu.Unlock()
+		return
+	}
+	d.notifying = true
+
+	// if a subscriber panics, the panic propagates to the goroutine that replaced the root,
+	// and later changes must still be notified
+	done := false
+	defer func() {
+		if !done {
+			d.mu.Lock()
+			d.notifying = false
+			d.mu.Unlock()
+		}
+	}()
+
+	for {
+		last, state := d.notified, d.state.Load()
+		if last == state {
+			d.notifying = false
+			done = true
+			d.mu.Unlock()
+			return
+		}
+		d.notified = state
+		subscriptions := d.subscriptions
+		d.mu.Unlock()
+
+		for _, sub := range subscriptions {
+			if sub.cancelled.Load() {
+				continue
+			}
+			old, new := last.root.Get(sub.props...), state.root.Get(sub.props...)
+			if !unchanged(old, new) {
+				sub.f(old, new)
+			}
+		}
+
+		d.mu.Lock()
+	}
+}
+
+// unchanged reports whether old and new hold the same value.
+// Subtrees that Set left untouched are shared between versions, so they are detected without comparing their contents.
+func unchanged(old, new *Node) bool {
+	if old.err == nil && new.err == nil && sameValue(old.value, new.value) {
+		return true
+	}
+	return old.Equal(new)
+}
+
+// sameValue reports whether a and b are the same object or array, or the same scalar.
+func sameValue(a, b jsonvalue) bool {
+	switch typedA := a.(type) {
+	case []any:
+		typedB, ok := b.([]any)
+		return ok && len(typedA) == len(typedB) && reflect.ValueOf(typedA).UnsafePointer() == reflect.ValueOf(typedB).UnsafePointer()
+	case map[string]any:
+		typedB, ok := b.(map[string]any)
+		return ok && reflect.ValueOf(typedA).UnsafePointer() == reflect.ValueOf(typedB).UnsafePointer()
+	default:
+		return a == b
+	}
+}
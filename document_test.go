@@ -0,0 +1,203 @@
+package jsond
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestDocumentUpdate(t *testing.T) {
+	doc := NewDocument(Parse([]byte(`{"count": 0}`)))
+	before := doc.Load()
+
+	const goroutines, updates = 8, 100
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < updates; j++ {
+				_, err := doc.Update(func(n *Node) *Node {
+					count, err := UnmarshalNode[int](n.Get("count"))
+					if err != nil {
+						return n.withError(err)
+					}
+					return n.Set(count+1, "count")
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	root, version := doc.Snapshot()
+	if got, _ := UnmarshalNode[int](root.Get("count")); got != goroutines*updates {
+		t.Errorf("\ngot  %d\nwant %d", got, goroutines*updates)
+	}
+	if version != goroutines*updates {
+		t.Errorf("\ngot  %d\nwant %d", version, goroutines*updates)
+	}
+	if got, _ := UnmarshalNode[int](before.Get("count")); got != 0 {
+		t.Errorf("snapshot was modified: got %d", got)
+	}
+}
+
+func TestDocumentUpdateError(t *testing.T) {
+	doc := NewDocument(Parse([]byte(`{"a": 1}`)))
+	root := doc.Load()
+
+	_, err := doc.Update(func(n *Node) *Node {
+		return n.Set(2, "missing", "b")
+	})
+	want := "cannot set properties of undefined (setting 'b') at $['missing']['b']"
+	if err == nil || err.Error() != want {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+
+	// failed and no-op updates leave the document unchanged
+	if _, err := doc.Update(func(n *Node) *Node { return n }); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Load() != root || doc.Version() != 0 {
+		t.Errorf("\ngot  %p at version %d\nwant %p at version 0", doc.Load(), doc.Version(), root)
+	}
+}
+
+func TestDocumentCompareAndSwap(t *testing.T) {
+	doc := NewDocument(Parse([]byte(`{"a": 1}`)))
+	v0 := doc.Load()
+	v1 := v0.Set(2, "a")
+
+	tests := []struct {
+		name        string
+		old, new    *Node
+		want        bool
+		wantVersion uint64
+	}{
+		{name: "current root", old: v0, new: v1, want: true, wantVersion: 1},
+		{name: "stale root", old: v0, new: v0.Set(3, "a"), want: false, wantVersion: 1},
+		{name: "equal but not identical root", old: v0.Set(2, "a"), new: v0, want: false, wantVersion: 1},
+		{name: "new root with an error", old: v1, new: v1.Get("missing"), want: false, wantVersion: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := doc.CompareAndSwap(tt.old, tt.new); got != tt.want {
+				t.Errorf("\ngot  %v\nwant %v", got, tt.want)
+			}
+			if got := doc.Version(); got != tt.wantVersion {
+				t.Errorf("\ngot  %d\nwant %d", got, tt.wantVersion)
+			}
+		})
+	}
+
+	if doc.Load() != v1 {
+		t.Errorf("\ngot  %p\nwant %p", doc.Load(), v1)
+	}
+}
+
+func TestDocumentSubscribe(t *testing.T) {
+	doc := NewDocument(Parse([]byte(`{"db": {"host": "a", "port": 1}, "cache": {"size": 10}, "flags": []}`)))
+
+	var got []string
+	record := func(name string) func(old, new *Node) {
+		return func(old, new *Node) {
+			o, _ := old.Marshal()
+			n, _ := new.Marshal()
+			got = append(got, fmt.Sprintf("%s: %s -> %s", name, o, n))
+		}
+	}
+	doc.Subscribe(record("db"), "db")
+	doc.Subscribe(record("cache"), "cache", "size")
+	doc.Subscribe(record("new"), "new")
+	cancel := doc.Subscribe(record("root"))
+
+	updates := []func(n *Node) *Node{
+		func(n *Node) *Node { return n.Set("b", "db", "host") },
+		func(n *Node) *Node { return n.Set(10, "cache", "size") },
+		func(n *Node) *Node { return n.Set(true, "flags", 0) },
+		func(n *Node) *Node { return n.Set(map[string]any{"host": "b", "port": 1}, "db") },
+		func(n *Node) *Node { return n.Set(1, "new") },
+	}
+	for i, update := range updates {
+		if i == 2 {
+			cancel()
+		}
+		if _, err := doc.Update(update); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		`db: {"host":"a","port":1} -> {"host":"b","port":1}`,
+		`root: {"cache":{"size":10},"db":{"host":"a","port":1},"flags":[]} -> {"cache":{"size":10},"db":{"host":"b","port":1},"flags":[]}`,
+		`new:  -> 1`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestDocumentSubscribePanic(t *testing.T) {
+	doc := NewDocument(Parse([]byte(`{"a": 0}`)))
+
+	var got []string
+	doc.Subscribe(func(old, new *Node) {
+		v, _ := UnmarshalNode[int](new)
+		if v == 1 {
+			panic("subscriber failed")
+		}
+		got = append(got, fmt.Sprint(v))
+	}, "a")
+
+	func() {
+		defer func() {
+			if r := recover(); r != "subscriber failed" {
+				t.Errorf("\ngot  %v\nwant subscriber failed", r)
+			}
+		}()
+		doc.Update(func(n *Node) *Node { return n.Set(1, "a") })
+	}()
+
+	// the panic does not stop later notifications
+	if _, err := doc.Update(func(n *Node) *Node { return n.Set(2, "a") }); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestDocumentSubscribeUpdate(t *testing.T) {
+	doc := NewDocument(Parse([]byte(`{"a": 0, "b": 0}`)))
+
+	var got []string
+	doc.Subscribe(func(old, new *Node) {
+		v, _ := UnmarshalNode[int](new)
+		if _, err := doc.Update(func(n *Node) *Node { return n.Set(v*10, "b") }); err != nil {
+			t.Error(err)
+		}
+		got = append(got, fmt.Sprintf("a=%d", v))
+	}, "a")
+	doc.Subscribe(func(old, new *Node) {
+		v, _ := UnmarshalNode[int](new)
+		got = append(got, fmt.Sprintf("b=%d", v))
+	}, "b")
+
+	if _, err := doc.Update(func(n *Node) *Node { return n.Set(1, "a") }); err != nil {
+		t.Fatal(err)
+	}
+
+	// the update made by the first subscriber is reported after the current round of notifications
+	want := []string{"a=1", "b=10"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %q\nwant %q", got, want)
+	}
+	if doc.Version() != 2 {
+		t.Errorf("\ngot  %d\nwant 2", doc.Version())
+	}
+}
@@ -0,0 +1,390 @@
+package jsond
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	nodeType          = reflect.TypeOf(Node{})
+)
+
+// startDetectingCyclesAfter is the pointer depth after which the encoder checks for cycles, as in encoding/json.
+const startDetectingCyclesAfter = 1000
+
+// encoder converts Go values into jsonvalues through reflection, following the rules of json.Marshal,
+// so that Set does not need to marshal the value and parse it again.
+// Unlike json.Marshal, integers are kept as int64 or uint64 instead of becoming float64.
+type encoder struct {
+	ptrLevel  int
+	ptrSeen   map[any]struct{}
+	copyNodes bool // copy the values of Nodes, so that the result shares nothing with them
+}
+
+// encodeValue converts v into a jsonvalue.
+func encodeValue(v any) (jsonvalue, error) {
+	e := &encoder{}
+	return e.value(reflect.ValueOf(v))
+}
+
+// goValueError is an error in converting a value within a Go value, with the Go path to it, such as Servers[1].Port.
+type goValueError struct {
+	path []string // path elements in reverse order, since they are added while returning from the value
+	err  error
+}
+
+func (e *goValueError) Error() string {
+	var sb strings.Builder
+	for i := len(e.path) - 1; i >= 0; i-- {
+		sb.WriteString(e.path[i])
+	}
+	return fmt.Sprintf("Go value %s: %v", strings.TrimPrefix(sb.String(), "."), e.err)
+}
+
+func (e *goValueError) Unwrap() error {
+	return e.err
+}
+
+// withGoPath adds the path element elem in front of the path of err.
+func withGoPath(err error, elem string) error {
+	if goErr, ok := err.(*goValueError); ok {
+		goErr.path = append(goErr.path, elem)
+		return goErr
+	}
+	return &goValueError{path: []string{elem}, err: err}
+}
+
+func (e *encoder) value(v reflect.Value) (jsonvalue, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	t := v.Type()
+	c := lookupConverter(t)
+	switch {
+	case t.Kind() == reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return e.value(v.Elem())
+	case t == nodeType:
+		node := v.Interface().(Node)
+		return e.nodeValue(&node)
+	case t.Kind() == reflect.Pointer && t.Elem() == nodeType:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return e.nodeValue(v.Interface().(*Node))
+	case c != nil && c.encode != nil:
+		return e.converted(v, c)
+	case t.Kind() == reflect.Pointer && hasEncoder(lookupConverter(t.Elem())):
+		// pointers to a type with a converter are converted with it, rather than with the pointer's methods
+		if v.IsNil() {
+			return nil, nil
+		}
+		return e.value(v.Elem())
+	case t.Implements(jsonMarshalerType):
+		return e.marshaler(v)
+	case t.Kind() != reflect.Pointer && v.CanAddr() && reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return e.marshaler(v.Addr())
+	case t.Implements(textMarshalerType):
+		return e.textMarshaler(v)
+	case t.Kind() != reflect.Pointer && v.CanAddr() && reflect.PointerTo(t).Implements(textMarshalerType):
+		return e.textMarshaler(v.Addr())
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, &json.UnsupportedValueError{Value: v, Str: strconv.FormatFloat(f, 'g', -1, t.Bits())}
+		}
+		if t.Kind() == reflect.Float32 {
+			// use the shortest decimal representation of the float32, as json.Marshal does
+			f, _ = strconv.ParseFloat(strconv.FormatFloat(f, 'g', -1, 32), 64)
+		}
+		return f, nil
+
+	case reflect.String:
+		if t == jsonNumberType {
+			n := json.Number(v.String())
+			if n == "" {
+				n = "0"
+			}
+			if !isValidNumber(n) {
+				return nil, fmt.Errorf("json: invalid number literal %q", n)
+			}
+			return n, nil
+		}
+		return v.String(), nil
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if err := e.enter(v); err != nil {
+			return nil, err
+		}
+		defer e.leave(v)
+		return e.value(v.Elem())
+
+	case reflect.Struct:
+		return e.structValue(v)
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if err := e.enter(v); err != nil {
+			return nil, err
+		}
+		defer e.leave(v)
+		return e.mapValue(v)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t.Elem()).Implements(jsonMarshalerType) && !reflect.PointerTo(t.Elem()).Implements(textMarshalerType) {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+		if err := e.enter(v); err != nil {
+			return nil, err
+		}
+		defer e.leave(v)
+		return e.array(v)
+
+	case reflect.Array:
+		return e.array(v)
+
+	default:
+		return nil, &json.UnsupportedTypeError{Type: t}
+	}
+}
+
+// enter records that the encoder descends into the pointer, map or slice v, and reports a cycle if it already did.
+func (e *encoder) enter(v reflect.Value) error {
+	e.ptrLevel++
+	if e.ptrLevel <= startDetectingCyclesAfter {
+		return nil
+	}
+
+	key := cycleKey(v)
+	if e.ptrSeen == nil {
+		e.ptrSeen = map[any]struct{}{}
+	}
+	if _, ok := e.ptrSeen[key]; ok {
+		e.ptrLevel--
+		return (&json.UnsupportedValueError{Value: v, Str: fmt.Sprintf("encountered a cycle via %s", v.Type())})
+	}
+	e.ptrSeen[key] = struct{}{}
+	return nil
+}
+
+func (e *encoder) leave(v reflect.Value) {
+	if e.ptrLevel > startDetectingCyclesAfter {
+		delete(e.ptrSeen, cycleKey(v))
+	}
+	e.ptrLevel--
+}
+
+// cycleKey identifies the pointer, map or slice v. A slice is identified by its pointer and length, since subslices share the pointer.
+func cycleKey(v reflect.Value) any {
+	if v.Kind() == reflect.Slice {
+		return struct {
+			ptr unsafe.Pointer
+			len int
+		}{v.UnsafePointer(), v.Len()}
+	}
+	return v.UnsafePointer()
+}
+
+// nodeValue returns the value of a Node within the Go value.
+func (e *encoder) nodeValue(node *Node) (jsonvalue, error) {
+	if e.copyNodes {
+		return copyValue(node.value), node.err
+	}
+	return node.value, node.err
+}
+
+// hasEncoder reports whether c converts values into JSON values.
+func hasEncoder(c *converter) bool {
+	return c != nil && c.encode != nil
+}
+
+// converted converts v with the converter registered for its type.
+func (e *encoder) converted(v reflect.Value, c *converter) (jsonvalue, error) {
+	result, err := c.encode(v)
+	if err != nil {
+		return nil, err
+	}
+	if reflect.TypeOf(result) == v.Type() {
+		return nil, fmt.Errorf("converter for %s returned a value of the same type", v.Type())
+	}
+	return e.value(reflect.ValueOf(result))
+}
+
+// marshaler converts v with its json.Marshaler. Numbers in the result are kept as json.Number, so that they do not lose precision.
+func (e *encoder) marshaler(v reflect.Value) (jsonvalue, error) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, nil
+	}
+
+	data, err := v.Interface().(json.Marshaler).MarshalJSON()
+	if err != nil {
+		return nil, &json.MarshalerError{Type: v.Type(), Err: err}
+	}
+
+	node := Parse(data, UseNumber())
+	if node.err != nil {
+		return nil, &json.MarshalerError{Type: v.Type(), Err: node.err}
+	}
+	return node.value, nil
+}
+
+func (e *encoder) textMarshaler(v reflect.Value) (jsonvalue, error) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil, nil
+	}
+
+	text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return nil, fmt.Errorf("json: error calling MarshalText for type %s: %w", v.Type(), err)
+	}
+	return string(text), nil
+}
+
+func (e *encoder) structValue(v reflect.Value) (jsonvalue, error) {
+	object := map[string]any{}
+
+fields:
+	for _, f := range cachedTypeFields(v.Type()).list {
+		// the field is skipped if an embedded pointer on its way is nil
+		fv := v
+		for _, i := range f.index {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue fields
+				}
+				fv = fv.Elem()
+			}
+			fv = fv.Field(i)
+		}
+
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+
+		value, err := e.value(fv)
+		if err != nil {
+			return nil, withGoPath(err, "."+v.Type().FieldByIndex(f.index).Name)
+		}
+		if f.quoted {
+			value = quotedValue(value)
+		}
+		object[f.name] = value
+	}
+	return object, nil
+}
+
+// quotedValue returns the value of a field with the ",string" option, which encodes scalars in a JSON string.
+func quotedValue(v jsonvalue) jsonvalue {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case string:
+		data, _ := json.Marshal(t)
+		return string(data)
+	default:
+		return numberLiteral(t)
+	}
+}
+
+func (e *encoder) mapValue(v reflect.Value) (jsonvalue, error) {
+	kt := v.Type().Key()
+	switch {
+	case kt.Kind() == reflect.String:
+	case kt.Implements(textMarshalerType):
+	case kt.Kind() >= reflect.Int && kt.Kind() <= reflect.Uintptr:
+	default:
+		return nil, &json.UnsupportedTypeError{Type: v.Type()}
+	}
+
+	object := make(map[string]any, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k := iter.Key()
+
+		var key string
+		switch {
+		case kt.Kind() == reflect.String:
+			key = k.String()
+		case kt.Implements(textMarshalerType):
+			if kt.Kind() == reflect.Pointer && k.IsNil() {
+				key = ""
+				break
+			}
+			text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return nil, withGoPath(fmt.Errorf("json: error calling MarshalText for type %s: %w", kt, err), fmt.Sprintf("[%#v]", k.Interface()))
+			}
+			key = string(text)
+		case kt.Kind() >= reflect.Int && kt.Kind() <= reflect.Int64:
+			key = strconv.FormatInt(k.Int(), 10)
+		default:
+			key = strconv.FormatUint(k.Uint(), 10)
+		}
+
+		value, err := e.value(iter.Value())
+		if err != nil {
+			return nil, withGoPath(err, fmt.Sprintf("[%#v]", k.Interface()))
+		}
+		object[key] = value
+	}
+	return object, nil
+}
+
+func (e *encoder) array(v reflect.Value) (jsonvalue, error) {
+	array := make([]any, v.Len())
+	for i := range array {
+		value, err := e.value(v.Index(i))
+		if err != nil {
+			return nil, withGoPath(err, fmt.Sprintf("[%d]", i))
+		}
+		array[i] = value
+	}
+	return array, nil
+}
+
+// isEmptyValue reports whether v is empty for the "omitempty" option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package jsond
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type encodeEmbedded struct {
+	ID int `json:"id"`
+}
+
+type encodeText struct{ s string }
+
+func (t encodeText) MarshalText() ([]byte, error) { return []byte("text:" + t.s), nil }
+
+type encodePtrMarshaler struct{ n int }
+
+func (m *encodePtrMarshaler) MarshalJSON() ([]byte, error) { return []byte(`{"ptr":true}`), nil }
+
+type encodeSource struct {
+	encodeEmbedded
+	*DecodeOther
+
+	Name     string                `json:"name"`
+	Count    int64                 `json:"count,string"`
+	Label    string                `json:"label,string"`
+	Ratio    float32               `json:"ratio"`
+	Empty    string                `json:"empty,omitempty"`
+	Nil      *int                  `json:"nil"`
+	Tags     []string              `json:"tags"`
+	NilTags  []string              `json:"nil_tags"`
+	Pair     [2]int8               `json:"pair"`
+	ByID     map[int]bool          `json:"by_id"`
+	ByText   map[encodeText]string `json:"by_text"`
+	Addr     net.IP                `json:"addr"`
+	When     time.Time             `json:"when"`
+	Raw      json.RawMessage       `json:"raw"`
+	Num      json.Number           `json:"num"`
+	Any      any                   `json:"any"`
+	Data     []byte                `json:"data"`
+	Node     *Node                 `json:"node"`
+	Ptr      encodePtrMarshaler    `json:"ptr"`
+	Skipped  string                `json:"-"`
+	Untagged uint8
+	private  int
+}
+
+func TestEncodeMatchesEncodingJSON(t *testing.T) {
+	source := &encodeSource{
+		encodeEmbedded: encodeEmbedded{ID: 7},
+		Name:           "n",
+		Count:          12,
+		Label:          "x",
+		Ratio:          0.1,
+		Tags:           []string{"a"},
+		Pair:           [2]int8{1, -2},
+		ByID:           map[int]bool{1: true, -2: false},
+		ByText:         map[encodeText]string{{s: "k"}: "v"},
+		Addr:           net.IPv4(10, 0, 0, 1),
+		When:           time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Raw:            json.RawMessage(`{"x": [1, 2]}`),
+		Num:            "1.50",
+		Any:            map[string]any{"a": []any{1, "x", nil, true}},
+		Data:           []byte("hello"),
+		Node:           Parse([]byte(`{"n": [1]}`)),
+		Skipped:        "x",
+		Untagged:       9,
+		private:        1,
+	}
+
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{name: "struct", value: source},
+		{name: "struct value", value: *source},
+		{name: "embedded pointer", value: &encodeSource{DecodeOther: &DecodeOther{Comment: "c", Extra: true}}},
+		{name: "slice", value: []any{1, 2.5, "s", nil, []int{}}},
+		{name: "map", value: map[string][]uint{"a": {1}}},
+		{name: "nil map", value: map[string]int(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := encodeValue(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !(&Node{value: got}).Equal(Parse(data)) {
+				gotData, _ := json.Marshal(got)
+				t.Errorf("\ngot  %s\nwant %s", gotData, data)
+			}
+		})
+	}
+}
+
+func TestEncodeKeepsIntegers(t *testing.T) {
+	got, err := encodeValue(struct {
+		I int64
+		U uint64
+		F float64
+	}{I: math.MaxInt64, U: math.MaxUint64, F: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{"I": int64(math.MaxInt64), "U": uint64(math.MaxUint64), "F": float64(1)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %#v\nwant %#v", got, want)
+	}
+}
+
+type encodeServer struct {
+	Port float64
+}
+
+type encodeConfig struct {
+	Servers []encodeServer
+	Labels  map[string]any
+}
+
+type encodeFailing struct{}
+
+func (encodeFailing) MarshalJSON() ([]byte, error) { return nil, errors.New("failed") }
+
+func TestEncodeErrors(t *testing.T) {
+	cyclic := map[string]any{}
+	cyclic["self"] = cyclic
+
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{
+			name:  "field path",
+			value: encodeConfig{Servers: []encodeServer{{Port: 80}, {Port: math.NaN()}}},
+			want:  "Go value Servers[1].Port: json: unsupported value: NaN",
+		},
+		{
+			name:  "map key",
+			value: &encodeConfig{Labels: map[string]any{"f": func() {}}},
+			want:  `Go value Labels["f"]: json: unsupported type: func()`,
+		},
+		{
+			name:  "marshaler",
+			value: []any{encodeFailing{}},
+			want:  "Go value [0]: json: error calling MarshalJSON for type jsond.encodeFailing: failed",
+		},
+		{
+			name:  "whole value",
+			value: make(chan int),
+			want:  "json: unsupported type: chan int",
+		},
+		{
+			// the error is reported once the pointer depth passes startDetectingCyclesAfter
+			name:  "cycle",
+			value: cyclic,
+			want:  "encountered a cycle via map[string]interface {}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := encodeValue(tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("\ngot  %v\nwant %s", err, tt.want)
+			}
+		})
+	}
+
+	want := "Go value Servers[0].Port: json: unsupported value: +Inf at $['config']"
+	err := Parse([]byte(`{}`)).Set(encodeConfig{Servers: []encodeServer{{Port: math.Inf(1)}}}, "config").Error()
+	if err == nil || err.Error() != want {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+}
@@ -0,0 +1,248 @@
+package jsond
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// EqualOption configures how JSON values are compared by Equal.
+type EqualOption func(*equalConfig)
+
+type equalConfig struct {
+	ignorePaths     []jsonpath
+	unorderedArrays bool
+	floatTolerance  float64
+}
+
+// IgnorePath makes Equal skip the value at the given property path.
+// The path is relative to the compared nodes.
+func IgnorePath(props ...any) EqualOption {
+	path := jsonpath{}
+	for _, prop := range props {
+		validProp, err := getProperty(prop)
+		if err != nil {
+			panic(fmt.Sprintf("invalid property. prop=%v, type=%T", prop, prop))
+		}
+		path = path.append(validProp)
+	}
+
+	return func(c *equalConfig) {
+		c.ignorePaths = append(c.ignorePaths, path)
+	}
+}
+
+// UnorderedArrays makes Equal compare arrays as multisets, ignoring the order of their elements.
+func UnorderedArrays() EqualOption {
+	return func(c *equalConfig) {
+		c.unorderedArrays = true
+	}
+}
+
+// FloatTolerance makes Equal treat numbers as equal when they differ by no more than tolerance.
+func FloatTolerance(tolerance float64) EqualOption {
+	return func(c *equalConfig) {
+		c.floatTolerance = tolerance
+	}
+}
+
+// Equal reports whether the Node and other represent the same JSON value.
+// Object key order is ignored and numbers are compared numerically.
+// A float64, such as a number parsed without UseNumber, is compared by its shortest decimal form.
+// Nodes with errors are equal if they are both undefined, or if they hold the same kind of error.
+// A nil other is not equal to any Node.
+func (n *Node) Equal(other *Node, opts ...EqualOption) bool {
+	if other == nil {
+		return false
+	}
+
+	cfg := equalConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if n.err != nil || other.err != nil {
+		return equalError(n.err, other.err)
+	}
+
+	return cfg.equal(jsonpath{}, n.value, other.value)
+}
+
+// Equal parses the JSON data a and b and reports whether they represent the same JSON value.
+// Numbers are parsed without loss of precision before being compared.
+func Equal(a, b []byte, opts ...EqualOption) (bool, error) {
+	nodeA := Parse(a, UseNumber())
+	if nodeA.err != nil {
+		return false, nodeA.err
+	}
+
+	nodeB := Parse(b, UseNumber())
+	if nodeB.err != nil {
+		return false, nodeB.err
+	}
+
+	return nodeA.Equal(nodeB, opts...), nil
+}
+
+func equalError(a, b error) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if IsUndefined(a) || IsUndefined(b) {
+		return IsUndefined(a) && IsUndefined(b)
+	}
+
+	var nodeErrA, nodeErrB *NodeError
+	if errors.As(a, &nodeErrA) && errors.As(b, &nodeErrB) {
+		return nodeErrA.code == nodeErrB.code &&
+			nodeErrA.err.Error() == nodeErrB.err.Error()
+	}
+
+	return a.Error() == b.Error()
+}
+
+func (c *equalConfig) isIgnored(path jsonpath) bool {
+	for _, ignorePath := range c.ignorePaths {
+		if len(ignorePath) != len(path) {
+			continue
+		}
+
+		matched := true
+		for i := range path {
+			if path[i] != ignorePath[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *equalConfig) equal(path jsonpath, a, b jsonvalue) bool {
+	if c.isIgnored(path) {
+		return true
+	}
+
+	if isNumber(a) && isNumber(b) {
+		return c.equalNumber(a, b)
+	}
+
+	switch typedA := a.(type) {
+	case nil:
+		return b == nil
+
+	case bool, string:
+		return a == b
+
+	case []any:
+		typedB, ok := b.([]any)
+		if !ok || len(typedA) != len(typedB) {
+			return false
+		}
+		if c.unorderedArrays {
+			return c.equalUnordered(path, typedA, typedB)
+		}
+		for i := range typedA {
+			if !c.equal(path.append(arrayIndex(i)), typedA[i], typedB[i]) {
+				return false
+			}
+		}
+		return true
+
+	case map[string]any:
+		typedB, ok := b.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, v := range typedA {
+			elemPath := path.append(objectKey(k))
+			w, ok := typedB[k]
+			if !ok {
+				if c.isIgnored(elemPath) {
+					continue
+				}
+				return false
+			}
+			if !c.equal(elemPath, v, w) {
+				return false
+			}
+		}
+		for k := range typedB {
+			if _, ok := typedA[k]; !ok && !c.isIgnored(path.append(objectKey(k))) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return false
+	}
+}
+
+// equalUnordered reports whether every element in a can be paired with a distinct equal element in b.
+func (c *equalConfig) equalUnordered(path jsonpath, a, b []any) bool {
+	used := make([]bool, len(b))
+
+	for i := range a {
+		elemPath := path.append(arrayIndex(i))
+		found := false
+		for j := range b {
+			if used[j] {
+				continue
+			}
+			if c.equal(elemPath, a[i], b[j]) {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *equalConfig) equalNumber(a, b jsonvalue) bool {
+	if c.floatTolerance > 0 {
+		fa, errA := numberFloat64(a)
+		fb, errB := numberFloat64(b)
+		if errA != nil || errB != nil {
+			return false
+		}
+		return math.Abs(fa-fb) <= c.floatTolerance
+	}
+
+	if fa, ok := a.(float64); ok {
+		if fb, ok := b.(float64); ok {
+			return fa == fb
+		}
+	}
+
+	ra, okA := decimalRat(a)
+	rb, okB := decimalRat(b)
+	if !okA || !okB {
+		return false
+	}
+	return ra.Cmp(rb) == 0
+}
+
+// decimalRat returns the JSON number v as a rational number for comparison.
+// A float64 only approximates the literal it was parsed from, so it is converted through its shortest decimal form,
+// which makes 0.1 parsed as a float64 equal to 0.1 parsed as a json.Number.
+func decimalRat(v jsonvalue) (*big.Rat, bool) {
+	f, ok := v.(float64)
+	if !ok {
+		return numberRat(v)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+	return new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+}
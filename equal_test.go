@@ -0,0 +1,103 @@
+package jsond
+
+import (
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		opts []EqualOption
+		want bool
+	}{
+		{name: "key order", a: `{"a":1,"b":[1,2]}`, b: `{"b":[1,2],"a":1}`, want: true},
+		{name: "number format", a: `[1, 100, 0.5]`, b: `[1.0, 1e2, 5E-1]`, want: true},
+		{name: "big integers", a: `12345678901234567890`, b: `12345678901234567891`, want: false},
+		{name: "different type", a: `{"a":1}`, b: `{"a":"1"}`, want: false},
+		{name: "missing key", a: `{"a":1}`, b: `{"a":1,"b":null}`, want: false},
+		{name: "array order", a: `[1,2,3]`, b: `[3,1,2]`, want: false},
+		{
+			name: "unordered arrays",
+			a:    `{"tags":["x","y",{"z":[1,2]}]}`,
+			b:    `{"tags":[{"z":[2,1]},"y","x"]}`,
+			opts: []EqualOption{UnorderedArrays()},
+			want: true,
+		},
+		{
+			name: "unordered arrays with duplicates",
+			a:    `[1,1,2]`,
+			b:    `[1,2,2]`,
+			opts: []EqualOption{UnorderedArrays()},
+			want: false,
+		},
+		{
+			name: "ignore path",
+			a:    `{"id":1,"meta":{"updated":"2023-01-01"}}`,
+			b:    `{"id":1,"meta":{"updated":"2024-01-01"}}`,
+			opts: []EqualOption{IgnorePath("meta", "updated")},
+			want: true,
+		},
+		{
+			name: "ignore missing path",
+			a:    `[{"id":1,"etag":"x"}]`,
+			b:    `[{"id":1}]`,
+			opts: []EqualOption{IgnorePath(0, "etag")},
+			want: true,
+		},
+		{
+			name: "float tolerance",
+			a:    `{"x":0.30000000000000004}`,
+			b:    `{"x":0.3}`,
+			opts: []EqualOption{FloatTolerance(1e-9)},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Equal([]byte(tt.a), []byte(tt.b), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("\ngot  %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodeEqual(t *testing.T) {
+	root := Parse([]byte(`{"a":{"b":null},"n":1}`))
+
+	tests := []struct {
+		name string
+		a    *Node
+		b    *Node
+		want bool
+	}{
+		{name: "set value", a: root.Set(2, "n"), b: Parse([]byte(`{"a":{"b":null},"n":2}`)), want: true},
+		{name: "lossless", a: root, b: Parse([]byte(`{"a":{"b":null},"n":1.0}`), UseNumber()), want: true},
+		{name: "float and number", a: Parse([]byte(`0.1`)), b: Parse([]byte(`0.1`), UseNumber()), want: true},
+		{name: "float and exponent", a: Parse([]byte(`[1e21, 2.5e-8]`)), b: Parse([]byte(`[1E+21, 0.000000025]`), UseNumber()), want: true},
+		{name: "float and integer", a: Parse([]byte(`3`)), b: Parse([]byte(`{}`)).Set(int64(3)), want: true},
+		{name: "float and different number", a: Parse([]byte(`0.1`)), b: Parse([]byte(`0.10000000000000001`), UseNumber()), want: false},
+		{name: "undefined", a: root.Get("x"), b: root.Get("a", "y"), want: true},
+		{name: "undefined and null", a: root.Get("x"), b: root.Get("a", "b"), want: false},
+		{name: "same error", a: root.Get("a", "b", "c"), b: root.Get("a", "b", "c"), want: true},
+		{name: "different error", a: root.Get("a", "b", "c"), b: root.Get("x", "c"), want: false},
+		{name: "nil", a: root, b: nil, want: false},
+		{name: "undefined and nil", a: root.Get("x"), b: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.a.Equal(tt.b)
+			if got != tt.want {
+				t.Errorf("\ngot  %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
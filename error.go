@@ -11,6 +11,7 @@ var _ error = (*NodeError)(nil)
 type NodeError struct {
 	code errCode
 	path jsonpath
+	pos  Position // position in the parsed input, if known
 	err  error
 }
 
@@ -26,13 +27,40 @@ const (
 	codeSetNullError
 	codeSetUndefinedError
 	codeCreatePopertyError
+	codeNotArrayError
+	codeCallbackError
+	codeSyntaxError
+	codeDuplicateKeyError
+	codeInvalidUTF8Error
+	codeTrailingDataError
+	codeInvalidIndexError
+	codeSelectorError
+	codeRefError
+	codeDeleteNullError
+	codeDeleteUndefinedError
+	codeValidationError
 )
 
 func (e NodeError) Error() string {
+	msg := e.err.Error()
+	if e.pos.IsValid() {
+		msg = fmt.Sprintf("%s: %s", e.pos.String(), msg)
+	}
 	if len(e.path) > 0 {
-		return fmt.Sprintf("%s at %s", e.err.Error(), e.path.String())
+		return fmt.Sprintf("%s at %s", msg, e.path.String())
 	}
-	return e.err.Error()
+	return msg
+}
+
+// Position returns the position in the parsed input where the error occurred.
+// It is only known for Nodes parsed with WithPositions.
+func (e NodeError) Position() Position {
+	return e.pos
+}
+
+// Unwrap returns the underlying error.
+func (e NodeError) Unwrap() error {
+	return e.err
 }
 
 func newInternalError(path jsonpath, err error) error {
@@ -43,6 +71,15 @@ func newInternalError(path jsonpath, err error) error {
 	}
 }
 
+// newMarshalError creates a new NodeError for a value that cannot be serialized.
+func newMarshalError(path jsonpath, err error) error {
+	return &NodeError{
+		code: codeMarshalError,
+		path: path,
+		err:  err,
+	}
+}
+
 // newReadNullError creates a new NodeError for attempting to read properties of null.
 func newReadNullError(path jsonpath) error {
 	prop := path[len(path)-1]
@@ -85,13 +122,128 @@ func newSetUndefinedError(path jsonpath) error {
 	}
 }
 
+func newDeleteNullError(path jsonpath) error {
+	prop := path[len(path)-1]
+
+	return &NodeError{
+		code: codeDeleteNullError,
+		path: path,
+		err:  fmt.Errorf("cannot delete properties of null (deleting '%v')", prop),
+	}
+}
+
+func newDeleteUndefinedError(path jsonpath) error {
+	prop := path[len(path)-1]
+
+	return &NodeError{
+		code: codeDeleteUndefinedError,
+		path: path,
+		err:  fmt.Errorf("cannot delete properties of undefined (deleting '%v')", prop),
+	}
+}
+
 func newCreatePopertyError(path jsonpath, parentValue jsonvalue) error {
 	prop := path[len(path)-1]
 
+	on := "null"
+	if parentValue != nil {
+		on = fmt.Sprintf("%s '%v'", getTypeString(parentValue), parentValue)
+	}
+
 	return &NodeError{
 		code: codeCreatePopertyError,
 		path: path,
-		err:  fmt.Errorf(`cannot create property '%v' on %s '%v'`, prop, getTypeString(parentValue), parentValue),
+		err:  fmt.Errorf(`cannot create property '%v' on %s`, prop, on),
+	}
+}
+
+// newNotArrayError creates a new NodeError for an operation that requires an array.
+func newNotArrayError(path jsonpath, value jsonvalue) error {
+	return &NodeError{
+		code: codeNotArrayError,
+		path: path,
+		err:  fmt.Errorf("%s is not an array", getTypeString(value)),
+	}
+}
+
+// newIndexOutOfRangeError creates a new NodeError for a negative index that does not refer to an element of the array.
+func newIndexOutOfRangeError(path jsonpath, length int) error {
+	prop := path[len(path)-1]
+
+	return &NodeError{
+		code: codeInvalidIndexError,
+		path: path,
+		err:  fmt.Errorf("index %v out of range for array of length %d", prop, length),
+	}
+}
+
+// newSelectorSetError creates a new NodeError for an attempt to set a value through a Selector or on a result set.
+func newSelectorSetError(path jsonpath) error {
+	return &NodeError{
+		code: codeSelectorError,
+		path: path,
+		err:  errors.New("cannot set values through a selector"),
+	}
+}
+
+// newRefError creates a new NodeError for a JSON Reference that cannot be resolved.
+func newRefError(path jsonpath, ref string, err error) error {
+	return &NodeError{
+		code: codeRefError,
+		path: path,
+		err:  fmt.Errorf("cannot resolve $ref %q: %w", ref, err),
+	}
+}
+
+// newCallbackError creates a new NodeError for an error returned by a user-supplied function.
+// If err is already a NodeError, it is returned as is.
+func newCallbackError(path jsonpath, err error) error {
+	var nodeErr *NodeError
+	if errors.As(err, &nodeErr) {
+		return err
+	}
+
+	return &NodeError{
+		code: codeCallbackError,
+		path: path,
+		err:  err,
+	}
+}
+
+// newSyntaxError creates a new NodeError for malformed input at the given line and column.
+func newSyntaxError(path jsonpath, line, column int, msg string) error {
+	return &NodeError{
+		code: codeSyntaxError,
+		path: path,
+		err:  fmt.Errorf("line %d, column %d: %s", line, column, msg),
+	}
+}
+
+// newDecodeError creates a new NodeError for malformed binary input at the given byte offset.
+func newDecodeError(path jsonpath, offset int, msg string) error {
+	return &NodeError{
+		code: codeSyntaxError,
+		path: path,
+		err:  fmt.Errorf("offset %d: %s", offset, msg),
+	}
+}
+
+// newYAMLError creates a new NodeError for a YAML document that cannot be converted.
+func newYAMLError(path jsonpath, err error) error {
+	return &NodeError{
+		code: codeSyntaxError,
+		path: path,
+		err:  err,
+	}
+}
+
+// newValidationError creates a new NodeError for a value that fails a check of a Validator.
+func newValidationError(path jsonpath, pos Position, err error) error {
+	return &NodeError{
+		code: codeValidationError,
+		path: path,
+		pos:  pos,
+		err:  err,
 	}
 }
 
@@ -1,7 +1,15 @@
 package jsond_test
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
 
 	"github.com/kmio11/jsond"
 )
@@ -302,3 +310,584 @@ func ExampleNode_Marshal() {
 	// Output:
 	// {"id":13,"name":"Test output"}
 }
+
+func ExampleNode_Canonical() {
+	src := []byte(`
+	{
+		"name": "Test output",
+		"id": 1.30E1,
+		"tags": ["a", "b"]
+	}
+	`)
+
+	b, _ := jsond.Parse(src).Canonical()
+
+	fmt.Println(string(b))
+
+	// Output:
+	// {"id":13,"name":"Test output","tags":["a","b"]}
+}
+
+func ExampleNode_Hash() {
+	a := jsond.Parse([]byte(`{"id": 13, "name": "Test output"}`))
+	b := jsond.Parse([]byte(`{"name":"Test output","id":1.3e1}`))
+
+	hashA, _ := a.Hash(sha256.New())
+	hashB, _ := b.Hash(sha256.New())
+
+	fmt.Println(bytes.Equal(hashA, hashB))
+
+	// Output:
+	// true
+}
+
+func ExampleEqual() {
+	a := []byte(`{"id": 13, "tags": ["go", "json"]}`)
+	b := []byte(`{"tags": ["json", "go"], "id": 1.3e1}`)
+
+	ordered, _ := jsond.Equal(a, b)
+	unordered, _ := jsond.Equal(a, b, jsond.UnorderedArrays())
+
+	fmt.Println(ordered)
+	fmt.Println(unordered)
+
+	// Output:
+	// false
+	// true
+}
+
+func ExampleMap() {
+	src := []byte(`
+	{
+		"total_count": 2,
+		"artifacts": [
+		  {
+			"id": 11,
+			"name": "Rails"
+		  },
+		  {
+			"id": 13,
+			"name": "Test output"
+		  }
+		]
+	  }
+	`)
+
+	names, _ := jsond.Map(
+		jsond.Parse(src).Get("artifacts"),
+		func(n *jsond.Node) (string, error) {
+			return jsond.UnmarshalNode[string](n.Get("name"))
+		},
+	)
+
+	fmt.Println(names)
+
+	// Output:
+	// [Rails Test output]
+}
+
+func ExampleNode_Filter() {
+	src := []byte(`
+	{
+		"total_count": 2,
+		"artifacts": [
+		  {
+			"id": 11,
+			"name": "Rails"
+		  },
+		  {
+			"id": 13,
+			"name": "Test output"
+		  }
+		]
+	  }
+	`)
+
+	b, _ := jsond.Parse(src).
+		Get("artifacts").
+		Filter(func(n *jsond.Node) bool {
+			id, _ := jsond.UnmarshalNode[int](n.Get("id"))
+			return id > 12
+		}).
+		Marshal()
+
+	fmt.Println(string(b))
+
+	// Output:
+	// [{"id":13,"name":"Test output"}]
+}
+
+func ExampleReduce() {
+	src := []byte(`
+	{
+		"total_count": 2,
+		"artifacts": [
+		  {
+			"id": 11,
+			"name": "Rails"
+		  },
+		  {
+			"id": 13,
+			"name": "Test output"
+		  }
+		]
+	  }
+	`)
+
+	sum, _ := jsond.Reduce(
+		jsond.Parse(src).Get("artifacts"),
+		0,
+		func(acc int, n *jsond.Node) (int, error) {
+			id, err := jsond.UnmarshalNode[int](n.Get("id"))
+			return acc + id, err
+		},
+	)
+
+	fmt.Println(sum)
+
+	// Output:
+	// 24
+}
+
+func ExampleNode_MarshalJSON() {
+	type Event struct {
+		Type    string      `json:"type"`
+		Payload *jsond.Node `json:"payload"`
+	}
+
+	var event Event
+	_ = json.Unmarshal([]byte(`{"type":"push","payload":{"ref":"main","size":2}}`), &event)
+
+	event.Payload = event.Payload.Set("develop", "ref")
+
+	b, _ := json.Marshal(event)
+
+	fmt.Println(string(b))
+
+	// Output:
+	// {"type":"push","payload":{"ref":"develop","size":2}}
+}
+
+func ExampleLineReader() {
+	src := strings.NewReader(`{"id": 11, "name": "Rails"}
+{"id": 13, "name": "Test output"}
+`)
+
+	r := jsond.NewLineReader(src)
+	for {
+		node, err := r.Read()
+		if err != nil {
+			if err != io.EOF {
+				fmt.Println(err)
+			}
+			break
+		}
+
+		name, _ := jsond.UnmarshalNode[string](node.Get("name"))
+		fmt.Printf("%d : %s\n", r.Line(), name)
+	}
+
+	// Output:
+	// 1 : Rails
+	// 2 : Test output
+}
+
+func ExampleParseRelaxed() {
+	src := []byte(`
+	{
+		// compiler settings
+		compilerOptions: {
+			target: 'es2020',
+			strict: true, /* trailing comma */
+		},
+	}
+	`)
+
+	b, _ := jsond.ParseRelaxed(src).Marshal()
+
+	fmt.Println(string(b))
+
+	// Output:
+	// {"compilerOptions":{"strict":true,"target":"es2020"}}
+}
+
+func ExampleParseYAML() {
+	src := []byte(`
+total_count: 2
+artifacts:
+  - id: 11
+    name: Rails
+  - id: 13
+    name: Test output
+`)
+
+	var name string
+	_ = jsond.ParseYAML(src).
+		Get("artifacts", 1, "name").
+		Unmarshal(&name)
+
+	fmt.Println(name)
+
+	// Output:
+	// Test output
+}
+
+func ExampleNode_MarshalYAML() {
+	src := []byte(`
+	{
+		"total_count": 2,
+		"artifacts": [
+		  {
+			"id": 11,
+			"name": "Rails"
+		  },
+		  {
+			"id": 13,
+			"name": "Test output"
+		  }
+		]
+	  }
+	`)
+
+	b, _ := jsond.Parse(src).MarshalYAML()
+
+	fmt.Print(string(b))
+
+	// Output:
+	// artifacts:
+	//   - id: 11
+	//     name: Rails
+	//   - id: 13
+	//     name: Test output
+	// total_count: 2
+}
+
+func ExampleParseCBOR() {
+	// {"name": "web", "ports": [80, 443]}
+	src := []byte{
+		0xa2,
+		0x64, 'n', 'a', 'm', 'e', 0x63, 'w', 'e', 'b',
+		0x65, 'p', 'o', 'r', 't', 's', 0x82, 0x18, 0x50, 0x19, 0x01, 0xbb,
+	}
+
+	b, _ := jsond.ParseCBOR(src).Get("ports").Marshal()
+
+	fmt.Println(string(b))
+
+	// Output:
+	// [80,443]
+}
+
+func ExampleNode_MarshalCBOR() {
+	src := []byte(`{"id": 11, "ratio": 0.5}`)
+
+	// with UseNumber, integral numbers are encoded as CBOR integers rather than floats
+	b, _ := jsond.Parse(src, jsond.UseNumber()).MarshalCBOR()
+
+	fmt.Printf("%x\n", b)
+
+	// Output:
+	// a26269640b65726174696ff93800
+}
+
+func ExampleParseMsgpack() {
+	// {"id": 9007199254740993, "name": "web"}
+	src := []byte{
+		0x82,
+		0xa2, 'i', 'd', 0xcf, 0x00, 0x20, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		0xa4, 'n', 'a', 'm', 'e', 0xa3, 'w', 'e', 'b',
+	}
+
+	var id int64
+	_ = jsond.ParseMsgpack(src).Get("id").Unmarshal(&id)
+
+	fmt.Println(id)
+
+	// Output:
+	// 9007199254740993
+}
+
+func ExampleWithPositions() {
+	src := []byte(`{
+  "spec": {
+    "replicas": "3"
+  }
+}`)
+
+	node := jsond.Parse(src, jsond.WithPositions("deploy.json"))
+	fmt.Println(node.Get("spec", "replicas").Position())
+
+	var replicas int
+	err := node.Get("spec", "replicas").Unmarshal(&replicas)
+	fmt.Println(err)
+
+	// Output:
+	// deploy.json:3:17
+	// deploy.json:3:17: json: cannot unmarshal string into Go value of type int at $['spec']['replicas']
+}
+
+func ExampleDisallowDuplicateKeys() {
+	src := []byte(`{"role": "user", "name": "alice", "role": "admin"}`)
+
+	node := jsond.Parse(src, jsond.DisallowDuplicateKeys())
+	if err := node.Error(); errors.Is(err, jsond.ErrDuplicateKey) {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// line 1, column 35: duplicate object key "role" at $['role']
+}
+
+func ExampleNode_Slice() {
+	src := []byte(`{"versions": ["1.0", "1.1", "2.0", "2.1", "3.0"]}`)
+	versions := jsond.Parse(src).Get("versions")
+
+	var latest string
+	_ = versions.Get(-1).Unmarshal(&latest)
+	fmt.Println(latest)
+
+	b, _ := versions.Slice(-3, math.MaxInt, 1).Marshal()
+	fmt.Println(string(b))
+
+	b, _ = versions.Slice(-1, math.MinInt, -2).Marshal()
+	fmt.Println(string(b))
+
+	// Output:
+	// 3.0
+	// ["2.0","2.1","3.0"]
+	// ["3.0","2.0","1.0"]
+}
+
+func ExampleRecursive() {
+	src := []byte(`{
+		"name": "root",
+		"children": [
+			{"name": "a", "children": [{"name": "a1"}]},
+			{"name": "b"}
+		]
+	}`)
+
+	var names []string
+	_ = jsond.Parse(src).Get(jsond.Recursive("name")).Unmarshal(&names)
+	fmt.Println(names)
+
+	// Output:
+	// [a1 a b root]
+}
+
+func ExampleWhere() {
+	src := []byte(`{"users": [{"name": "alice", "admin": true}, {"name": "bob", "admin": false}]}`)
+
+	admins := jsond.Parse(src).Get("users", jsond.Where(func(n *jsond.Node) bool {
+		admin, _ := jsond.UnmarshalNode[bool](n.Get("admin"))
+		return admin
+	}), "name")
+
+	for _, n := range admins.Nodes() {
+		name, _ := jsond.UnmarshalNode[string](n)
+		fmt.Println(name)
+	}
+
+	// Output:
+	// alice
+}
+
+func ExampleNode_ResolveRefs() {
+	src := []byte(`{
+		"user": {"$ref": "defs.json#/user"},
+		"admin": {"$ref": "#/user"}
+	}`)
+	resolver := jsond.MemoryResolver{
+		"defs.json": jsond.Parse([]byte(`{"user": {"type": "object", "required": ["name"]}}`)),
+	}
+
+	b, _ := jsond.Parse(src).ResolveRefs(resolver).Marshal()
+	fmt.Println(string(b))
+
+	// Output:
+	// {"admin":{"required":["name"],"type":"object"},"user":{"required":["name"],"type":"object"}}
+}
+
+func ExampleBuilder() {
+	user := jsond.Parse([]byte(`{"id": 1, "name": "alice"}`))
+
+	b := jsond.NewBuilder().
+		Set("ok", "status").
+		Set([]any{}, "users").
+		Set(user, "users", 0).
+		Set(true, "users", 0, "admin")
+
+	response := b.Freeze()
+	data, _ := response.Marshal()
+	fmt.Println(string(data))
+
+	// Output:
+	// {"status":"ok","users":[{"admin":true,"id":1,"name":"alice"}]}
+}
+
+func ExampleDocument() {
+	config := jsond.NewDocument(jsond.Parse([]byte(`{"flags": {"newCheckout": false}}`)))
+	snapshot := config.Load()
+
+	_, err := config.Update(func(root *jsond.Node) *jsond.Node {
+		return root.Set(true, "flags", "newCheckout")
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	before, _ := jsond.UnmarshalNode[bool](snapshot.Get("flags", "newCheckout"))
+	after, _ := jsond.UnmarshalNode[bool](config.Load().Get("flags", "newCheckout"))
+	fmt.Println(before, after, config.Version())
+
+	// Output:
+	// false true 1
+}
+
+func ExampleDocument_Subscribe() {
+	config := jsond.NewDocument(jsond.Parse([]byte(`{"database": {"host": "db1"}, "cache": {"size": 10}}`)))
+
+	config.Subscribe(func(old, new *jsond.Node) {
+		host, _ := jsond.UnmarshalNode[string](new.Get("host"))
+		fmt.Println("reconnect to", host)
+	}, "database")
+
+	config.Update(func(root *jsond.Node) *jsond.Node {
+		return root.Set(20, "cache", "size")
+	})
+	config.Update(func(root *jsond.Node) *jsond.Node {
+		return root.Set("db2", "database", "host")
+	})
+
+	// Output:
+	// reconnect to db2
+}
+
+func ExampleHistory() {
+	h := jsond.NewHistory(jsond.Parse([]byte(`{"title": "draft", "tags": ["a", "b"]}`)))
+
+	h.Set("final", "title")
+	h.Delete("tags", 0)
+	printCurrent := func() {
+		data, _ := h.Current().Marshal()
+		fmt.Println(string(data))
+	}
+	printCurrent()
+
+	edit, _ := h.Undo()
+	fmt.Println("undo", edit)
+	printCurrent()
+
+	// Output:
+	// {"tags":["b"],"title":"final"}
+	// undo delete $['tags'][0]
+	// {"tags":["a","b"],"title":"final"}
+}
+
+func ExampleRegisterConverter() {
+	type Celsius float64
+
+	jsond.RegisterConverter(
+		func(n *jsond.Node) (Celsius, error) {
+			s, err := jsond.UnmarshalNode[string](n)
+			if err != nil {
+				return 0, err
+			}
+			var c Celsius
+			_, err = fmt.Sscanf(s, "%g°C", &c)
+			return c, err
+		},
+		func(c Celsius) (any, error) {
+			return fmt.Sprintf("%g°C", c), nil
+		},
+	)
+
+	var reading struct {
+		Temperature Celsius   `json:"temperature"`
+		Time        time.Time `json:"time"`
+	}
+	_ = jsond.Parse([]byte(`{"temperature": "21.5°C", "time": "2024-01-02T03:04:05Z"}`)).Unmarshal(&reading)
+	fmt.Println(float64(reading.Temperature), reading.Time.Hour())
+
+	reading.Temperature += 2
+	data, _ := jsond.Parse([]byte(`{}`)).Set(reading).Marshal()
+	fmt.Println(string(data))
+
+	// Output:
+	// 21.5 3
+	// {"temperature":"23.5°C","time":"2024-01-02T03:04:05Z"}
+}
+
+func ExampleValidator() {
+	request := jsond.Parse([]byte(`{
+		"user": {"name": 1},
+		"items": [{"id": "a", "quantity": 3}, {"quantity": 0}]
+	}`))
+
+	err := jsond.NewValidator().
+		Required("user", "email").
+		Type(jsond.KindString, "user", "name").
+		Required("items", jsond.All, "id").
+		Range(1, 99, "items", jsond.All, "quantity").
+		Validate(request)
+
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// required value is missing at $['user']['email']
+	// expected string, but got number at $['user']['name']
+	// required value is missing at $['items'][1]['id']
+	// 0 is out of range [1, 99] at $['items'][1]['quantity']
+}
+
+func ExampleNode_GetOptional() {
+	root := jsond.Parse([]byte(`{"user": {"address": null}}`))
+
+	fmt.Println(root.Get("user", "address", "city").Error())
+	fmt.Println(root.GetOptional("user", "address", "city").IsUndefined())
+
+	// Output:
+	// cannot read properties of null (reading 'city') at $['user']['address']['city']
+	// true
+}
+
+func ExampleNode_SetCreate() {
+	root := jsond.Parse([]byte(`{"spec": null}`))
+
+	show := func(n *jsond.Node) {
+		data, err := n.Marshal()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(string(data))
+	}
+	show(root.SetCreate(3, jsond.KeepValues, "spec", "replicas"))
+	show(root.SetCreate(3, jsond.ReplaceNull, "spec", "replicas"))
+	show(root.SetCreate("web", jsond.KeepValues, "spec_list", 1, "name"))
+
+	// Output:
+	// cannot create property 'replicas' on null at $['spec']['replicas']
+	// {"spec":{"replicas":3}}
+	// {"spec":null,"spec_list":[null,{"name":"web"}]}
+}
+
+func ExampleNode_Kind() {
+	root := jsond.Parse([]byte(`{"name": "jsond", "tags": ["json", "go"], "license": null}`), jsond.WithPositions(""))
+
+	for _, key := range root.OrderedKeys() {
+		node := root.Get(key)
+		fmt.Println(key, node.Kind(), node.Len())
+	}
+	fmt.Println(root.Keys())
+	fmt.Println(root.Has("tags", 1), root.Has("tags", 2))
+
+	// Output:
+	// name string 0
+	// tags array 2
+	// license null 0
+	// [license name tags]
+	// true false
+}
@@ -0,0 +1,242 @@
+package jsond
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// structField is a struct field encoded as a JSON object member, following the rules of encoding/json.
+type structField struct {
+	name      string
+	tagged    bool  // the name comes from a json tag
+	index     []int // index sequence for reflect.Value.FieldByIndex
+	typ       reflect.Type
+	omitEmpty bool
+	quoted    bool // the ",string" option applies to the field
+}
+
+// structFields are the JSON fields of a struct type.
+type structFields struct {
+	list   []structField
+	byName map[string]*structField
+}
+
+// lookup returns the field for the object key. Like encoding/json, it prefers an exact match,
+// and falls back to a case-insensitive one.
+func (fs *structFields) lookup(key string) *structField {
+	if f, ok := fs.byName[key]; ok {
+		return f
+	}
+	for i := range fs.list {
+		if strings.EqualFold(fs.list[i].name, key) {
+			return &fs.list[i]
+		}
+	}
+	return nil
+}
+
+var fieldCache sync.Map // map[reflect.Type]*structFields
+
+// cachedTypeFields returns the JSON fields of the struct type t.
+func cachedTypeFields(t reflect.Type) *structFields {
+	if fs, ok := fieldCache.Load(t); ok {
+		return fs.(*structFields)
+	}
+	fs, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return fs.(*structFields)
+}
+
+// typeFields returns the JSON fields of the struct type t, including the fields promoted from embedded structs.
+// Names hidden by the Go visibility rules for embedded fields, or ambiguous at the same depth, are dropped.
+func typeFields(t reflect.Type) *structFields {
+	// embedded structs are visited breadth first, so that shallower fields come first
+	current := []structField{}
+	next := []structField{{typ: t}}
+
+	// count of queued names for the current and next levels
+	var count, nextCount map[reflect.Type]int
+	visited := map[reflect.Type]bool{}
+
+	var fields []structField
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, f := range current {
+			if visited[f.typ] {
+				continue
+			}
+			visited[f.typ] = true
+
+			for i := 0; i < f.typ.NumField(); i++ {
+				sf := f.typ.Field(i)
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+					}
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+					// embedded structs with unexported types may still have exported fields
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				if !isValidTag(name) {
+					name = ""
+				}
+
+				index := make([]int, len(f.index)+1)
+				copy(index, f.index)
+				index[len(f.index)] = i
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+
+				quoted := false
+				if hasTagOption(opts, "string") {
+					switch ft.Kind() {
+					case reflect.Bool,
+						reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+						reflect.Float32, reflect.Float64,
+						reflect.String:
+						quoted = true
+					}
+				}
+
+				if name != "" || !sf.Anonymous || ft.Kind() != reflect.Struct {
+					field := structField{
+						name:      name,
+						tagged:    name != "",
+						index:     index,
+						typ:       ft,
+						omitEmpty: hasTagOption(opts, "omitempty"),
+						quoted:    quoted,
+					}
+					if field.name == "" {
+						field.name = sf.Name
+					}
+					fields = append(fields, field)
+					if count[f.typ] > 1 {
+						// the same struct is embedded more than once at this level, so its fields annihilate each other.
+						// a second copy is enough to make them ambiguous.
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+
+				// an untagged embedded struct is visited at the next level
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, structField{name: ft.Name(), index: index, typ: ft})
+				}
+			}
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		x := fields
+		if x[i].name != x[j].name {
+			return x[i].name < x[j].name
+		}
+		if len(x[i].index) != len(x[j].index) {
+			return len(x[i].index) < len(x[j].index)
+		}
+		if x[i].tagged != x[j].tagged {
+			return x[i].tagged
+		}
+		return lessIndex(x[i].index, x[j].index)
+	})
+
+	// keep the dominant field of each name
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		name := fields[i].name
+		for advance = 1; i+advance < len(fields); advance++ {
+			if fields[i+advance].name != name {
+				break
+			}
+		}
+		if advance == 1 {
+			out = append(out, fields[i])
+			continue
+		}
+		if dominant, ok := dominantField(fields[i : i+advance]); ok {
+			out = append(out, dominant)
+		}
+	}
+	fields = out
+
+	sort.Slice(fields, func(i, j int) bool {
+		return lessIndex(fields[i].index, fields[j].index)
+	})
+
+	fs := &structFields{
+		list:   fields,
+		byName: make(map[string]*structField, len(fields)),
+	}
+	for i := range fs.list {
+		fs.byName[fs.list[i].name] = &fs.list[i]
+	}
+	return fs
+}
+
+// dominantField returns the field that hides the others with the same name, which are sorted by depth and tagging.
+// It returns false if the shallowest fields are ambiguous.
+func dominantField(fields []structField) (structField, bool) {
+	if len(fields) > 1 && len(fields[0].index) == len(fields[1].index) && fields[0].tagged == fields[1].tagged {
+		return structField{}, false
+	}
+	return fields[0], true
+}
+
+func lessIndex(a, b []int) bool {
+	for k, x := range a {
+		if k >= len(b) {
+			return false
+		}
+		if x != b[k] {
+			return x < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// isValidTag reports whether name can be used as a JSON name in a struct tag.
+func isValidTag(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+			// backslash and quote chars are reserved, but otherwise any punctuation chars are allowed in a tag name.
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
+
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
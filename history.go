@@ -0,0 +1,187 @@
+package jsond
+
+import (
+	"fmt"
+)
+
+// History records the edits applied to a JSON value, so that they can be undone and redone.
+// Each edit keeps the values before and after it, which is cheap since edits share the parts of the value they leave untouched.
+//
+// A History is not safe for concurrent use.
+type History struct {
+	current     *Node
+	undo        []Edit
+	redo        []Edit
+	limit       int
+	checkpoints map[string]*Node
+}
+
+// Edit is an edit recorded in a History.
+type Edit struct {
+	// Op is "set", "delete" or "restore" for edits made by the methods of the same name,
+	// and the name given to Apply for other edits.
+	Op string
+	// Props is the property path of the edited value, relative to the root.
+	// It is empty for edits applied to the whole value.
+	Props []any
+	// Before and After are the roots before and after the edit.
+	Before, After *Node
+}
+
+// String returns the operation and the path of the edit, such as "set $['a'][0]".
+func (e Edit) String() string {
+	if len(e.Props) == 0 {
+		return e.Op
+	}
+
+	path := jsonpath{}
+	for _, prop := range e.Props {
+		validProp, err := getProperty(prop)
+		if err != nil {
+			return fmt.Sprintf("%s %v", e.Op, e.Props)
+		}
+		path = path.append(validProp)
+	}
+	return fmt.Sprintf("%s %s", e.Op, path.String())
+}
+
+// HistoryOption configures a History.
+type HistoryOption func(*History)
+
+// HistoryLimit limits the number of edits that can be undone. Older edits are discarded.
+// The default is no limit.
+func HistoryLimit(n int) HistoryOption {
+	return func(h *History) {
+		h.limit = n
+	}
+}
+
+// NewHistory returns a new History whose current value is root.
+// It panics if root has an error.
+func NewHistory(root *Node, opts ...HistoryOption) *History {
+	if root.err != nil {
+		panic(fmt.Sprintf("invalid history root. err=%v", root.err))
+	}
+
+	h := &History{
+		current:     root,
+		checkpoints: map[string]*Node{},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Current returns the current value.
+func (h *History) Current() *Node {
+	return h.current
+}
+
+// Set sets value at the given property path of the current value, as Node.Set does, and records the edit.
+// If an error occurs, the current value is left unchanged and the error is returned.
+func (h *History) Set(value any, props ...any) error {
+	return h.record(Edit{
+		Op:     "set",
+		Props:  append([]any{}, props...),
+		Before: h.current,
+		After:  h.current.Set(value, props...),
+	})
+}
+
+// Delete removes the value at the given property path of the current value, as Node.Delete does, and records the edit.
+// If an error occurs, the current value is left unchanged and the error is returned.
+func (h *History) Delete(props ...any) error {
+	return h.record(Edit{
+		Op:     "delete",
+		Props:  append([]any{}, props...),
+		Before: h.current,
+		After:  h.current.Delete(props...),
+	})
+}
+
+// Apply replaces the current value with the result of f, such as a patched value, and records the edit as op.
+// If f returns a Node with an error, the current value is left unchanged and the error is returned.
+func (h *History) Apply(op string, f func(*Node) *Node) error {
+	return h.record(Edit{
+		Op:     op,
+		Before: h.current,
+		After:  f(h.current),
+	})
+}
+
+// record makes edit.After the current value, unless it has an error.
+// Edits that return the value unchanged are not recorded.
+func (h *History) record(edit Edit) error {
+	if edit.After.err != nil {
+		return edit.After.err
+	}
+	if edit.After == edit.Before {
+		return nil
+	}
+
+	h.current = edit.After
+	h.undo = append(h.undo, edit)
+	if h.limit > 0 && len(h.undo) > h.limit {
+		h.undo = append([]Edit{}, h.undo[len(h.undo)-h.limit:]...)
+	}
+	h.redo = nil
+	return nil
+}
+
+// CanUndo reports whether there is an edit to undo.
+func (h *History) CanUndo() bool {
+	return len(h.undo) > 0
+}
+
+// CanRedo reports whether there is an undone edit to redo.
+func (h *History) CanRedo() bool {
+	return len(h.redo) > 0
+}
+
+// Undo reverts the last edit and returns it. It returns false if there is no edit to undo.
+func (h *History) Undo() (Edit, bool) {
+	if len(h.undo) == 0 {
+		return Edit{}, false
+	}
+
+	edit := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = append(h.redo, edit)
+	h.current = edit.Before
+	return edit, true
+}
+
+// Redo applies the last undone edit again and returns it. It returns false if there is no edit to redo.
+// Recording a new edit discards the edits that can be redone.
+func (h *History) Redo() (Edit, bool) {
+	if len(h.redo) == 0 {
+		return Edit{}, false
+	}
+
+	edit := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = append(h.undo, edit)
+	h.current = edit.After
+	return edit, true
+}
+
+// Checkpoint saves the current value under name, replacing any checkpoint with the same name.
+func (h *History) Checkpoint(name string) {
+	h.checkpoints[name] = h.current
+}
+
+// Restore returns to the value saved by Checkpoint under name.
+// The restore is recorded as an edit, so it can be undone like any other.
+func (h *History) Restore(name string) error {
+	saved, ok := h.checkpoints[name]
+	if !ok {
+		return fmt.Errorf("checkpoint %q not found", name)
+	}
+
+	return h.record(Edit{
+		Op:     "restore",
+		Before: h.current,
+		After:  saved,
+	})
+}
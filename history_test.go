@@ -0,0 +1,113 @@
+package jsond
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	h := NewHistory(Parse([]byte(`{"name": "app", "tags": ["a"]}`)))
+
+	assert := func(want string) {
+		t.Helper()
+		got, err := h.Current().Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("\ngot  %s\nwant %s", got, want)
+		}
+	}
+
+	if err := h.Set("b", "tags", 1); err != nil {
+		t.Fatal(err)
+	}
+	h.Checkpoint("saved")
+	if err := h.Delete("name"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Apply("patch", func(n *Node) *Node { return n.Set(1, "version") }); err != nil {
+		t.Fatal(err)
+	}
+	assert(`{"tags":["a","b"],"version":1}`)
+
+	var undone []string
+	for h.CanUndo() {
+		edit, _ := h.Undo()
+		undone = append(undone, edit.String())
+	}
+	if want := []string{"patch", "delete $['name']", "set $['tags'][1]"}; !reflect.DeepEqual(undone, want) {
+		t.Errorf("\ngot  %q\nwant %q", undone, want)
+	}
+	assert(`{"name":"app","tags":["a"]}`)
+
+	if edit, ok := h.Redo(); !ok || edit.String() != "set $['tags'][1]" {
+		t.Errorf("\ngot  %v, %v\nwant set $['tags'][1]", edit, ok)
+	}
+	assert(`{"name":"app","tags":["a","b"]}`)
+
+	// a new edit discards the edits that could be redone
+	if err := h.Set("x", "name"); err != nil {
+		t.Fatal(err)
+	}
+	if h.CanRedo() {
+		t.Error("redo is still possible after a new edit")
+	}
+
+	if err := h.Restore("saved"); err != nil {
+		t.Fatal(err)
+	}
+	assert(`{"name":"app","tags":["a","b"]}`)
+	h.Undo()
+	assert(`{"name":"x","tags":["a","b"]}`)
+}
+
+func TestHistoryErrors(t *testing.T) {
+	root := Parse([]byte(`{"a": null}`))
+	h := NewHistory(root)
+
+	tests := []struct {
+		name string
+		edit func() error
+		want string
+	}{
+		{name: "set", edit: func() error { return h.Set(1, "a", "b") }, want: "cannot set properties of null (setting 'b') at $['a']['b']"},
+		{name: "delete", edit: func() error { return h.Delete("a", "b") }, want: "cannot delete properties of null (deleting 'b') at $['a']['b']"},
+		{name: "apply", edit: func() error { return h.Apply("get", func(n *Node) *Node { return n.Get("x", "y") }) }, want: "cannot read properties of undefined (reading 'y') at $['x']['y']"},
+		{name: "restore", edit: func() error { return h.Restore("missing") }, want: `checkpoint "missing" not found`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.edit(); err == nil || err.Error() != tt.want {
+				t.Errorf("\ngot  %v\nwant %s", err, tt.want)
+			}
+		})
+	}
+
+	// failed edits and edits that change nothing are not recorded
+	if err := h.Delete("missing"); err != nil {
+		t.Fatal(err)
+	}
+	if h.Current() != root || h.CanUndo() {
+		t.Errorf("history changed: %v", h.undo)
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	h := NewHistory(Parse([]byte(`{"n": 0}`)), HistoryLimit(2))
+	for i := 1; i <= 5; i++ {
+		if err := h.Set(i, "n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count := 0
+	for h.CanUndo() {
+		h.Undo()
+		count++
+	}
+	if got, _ := UnmarshalNode[int](h.Current().Get("n")); count != 2 || got != 3 {
+		t.Errorf("\ngot  %d edits back to %d\nwant 2 edits back to 3", count, got)
+	}
+}
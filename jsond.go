@@ -1,6 +1,7 @@
 package jsond
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,33 +9,94 @@ import (
 
 // Node represents a node in the JSON data structure.
 type Node struct {
-	parent *Node
-	value  jsonvalue
-	path   jsonpath
-	err    error
+	parent    *Node
+	value     jsonvalue
+	path      jsonpath
+	err       error
+	positions *positionTable // nil unless parsed with WithPositions
+	results   []*Node        // selected Nodes, if the Node is a result set
+}
+
+// ParseOption configures how Parse reads JSON data.
+type ParseOption func(*parseConfig)
+
+type parseConfig struct {
+	useNumber bool
+	relaxed   bool
+	positions bool
+	filename  string
+
+	disallowDuplicateKeys bool
+	disallowInvalidUTF8   bool
+}
+
+// needsParser reports whether the configuration requires the hand-written parser instead of encoding/json.
+func (c parseConfig) needsParser() bool {
+	return c.relaxed || c.positions || c.disallowDuplicateKeys || c.disallowInvalidUTF8
+}
+
+// UseNumber makes Parse keep JSON numbers as json.Number instead of float64,
+// so that numbers are preserved without loss of precision.
+func UseNumber() ParseOption {
+	return func(c *parseConfig) {
+		c.useNumber = true
+	}
 }
 
 // Parse parses the given JSON data and returns a Node representing the parsed structure.
-func Parse(data []byte) *Node {
+func Parse(data []byte, opts ...ParseOption) *Node {
+	cfg := parseConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	value := *new(jsonvalue)
 	path := []property{}
 
-	err := unmarshal(path, data, &value)
+	var err error
+	var positions *positionTable
+	if cfg.needsParser() {
+		p := newParser(data, cfg)
+		value, err = p.parse()
+		positions = p.positions
+	} else if cfg.useNumber {
+		err = unmarshalUseNumber(path, data, &value)
+	} else {
+		err = unmarshal(path, data, &value)
+	}
 	return &Node{
-		parent: nil,
-		value:  value,
-		path:   path,
-		err:    err,
+		parent:    nil,
+		value:     value,
+		path:      path,
+		err:       err,
+		positions: positions,
 	}
 }
 
+// ParseRelaxed is like Parse, but also accepts the following JSONC / JSON5 extensions:
+//   - comments (// and /* */)
+//   - trailing commas in arrays and objects
+//   - single-quoted strings
+//   - unquoted object keys
+//   - hexadecimal numbers, and numbers with a leading '+' or a leading or trailing decimal point
+//
+// The resulting Node is the same as the one Parse returns for the equivalent JSON data.
+// Syntax errors report the line and column where they occurred.
+func ParseRelaxed(data []byte, opts ...ParseOption) *Node {
+	opts = append(opts[:len(opts):len(opts)], func(c *parseConfig) {
+		c.relaxed = true
+	})
+	return Parse(data, opts...)
+}
+
 // newChild creates a new child node with the given arguments.
 func (n *Node) newChild(value jsonvalue, prop property, err error) *Node {
 	return &Node{
-		parent: n,
-		value:  value,
-		path:   n.path.append(prop),
-		err:    err,
+		parent:    n,
+		value:     value,
+		path:      n.path.append(prop),
+		err:       err,
+		positions: n.positions,
 	}
 }
 
@@ -48,20 +110,24 @@ func (n *Node) Error() error {
 	return n.err
 }
 
+// getArrayElement returns the element at idx. A negative idx counts from the end of the array,
+// and the path of the returned Node has the resolved, non-negative index.
+// An index out of range returns an undefined Node.
 func (n *Node) getArrayElement(idx arrayIndex) *Node {
-	path := n.path.append(idx)
-
 	array, ok := n.value.([]any)
-	if !ok || int(idx) > len(array) {
-		return n.newChild(nil, idx, newUndefined(path))
+	if !ok {
+		return n.newChild(nil, idx, newUndefined(n.path.append(idx)))
 	}
 
-	return &Node{
-		parent: n,
-		value:  array[idx],
-		path:   path,
-		err:    nil,
+	resolved := idx
+	if resolved < 0 {
+		resolved += arrayIndex(len(array))
+	}
+	if resolved < 0 || int(resolved) >= len(array) {
+		return n.newChild(nil, idx, newUndefined(n.path.append(idx)))
 	}
+
+	return n.newChild(array[resolved], resolved, nil)
 }
 
 func (n *Node) getObjectValue(key objectKey) *Node {
@@ -81,6 +147,7 @@ func (n *Node) getObjectValue(key objectKey) *Node {
 }
 
 // Get retrieves a child node based on the specified property (index or key).
+// A property can also be a Selector, which makes the result a result set of all the selected nodes.
 // If no properties are provided, the current node is returned.
 // It supports nested property access using variadic parameters.
 // If multiple properties are provided, it recursively calls Get on each property.
@@ -95,6 +162,10 @@ func (n *Node) Get(props ...any) *Node {
 	}
 
 	prop := props[0]
+	if _, ok := prop.(Selector); ok || n.results != nil {
+		return n.getSelected(prop)
+	}
+
 	validProp, err := getProperty(prop)
 	if err != nil {
 		panic(fmt.Sprintf("invalid property. prop=%v, type=%t", prop, prop))
@@ -126,6 +197,37 @@ func (n *Node) Get(props ...any) *Node {
 	}
 }
 
+// GetOptional retrieves a child node like Get, but reads past null and undefined values like JavaScript's optional chaining (?.).
+// Instead of an error, it returns an undefined Node with the full requested path,
+// so that a deep lookup of optional values needs no error handling other than IsUndefined.
+// Errors other than undefined values are returned as with Get.
+func (n *Node) GetOptional(props ...any) *Node {
+	current := n
+	for i, prop := range props {
+		if _, ok := prop.(Selector); ok || current.results != nil {
+			// result sets already drop the Nodes for which a property does not exist
+			return current.Get(props[i:]...)
+		}
+
+		if current.err != nil && !current.IsUndefined() {
+			return current
+		}
+
+		if current.err == nil && current.value != nil {
+			current = current.Get(prop)
+			continue
+		}
+
+		validProp, err := getProperty(prop)
+		if err != nil {
+			panic(fmt.Sprintf("invalid property. prop=%v, type=%T", prop, prop))
+		}
+		path := current.path.append(validProp)
+		current = current.newChild(nil, validProp, newUndefined(path))
+	}
+	return current
+}
+
 // AsArray returns the Node's value as an array of child nodes.
 func (n *Node) AsArray() ([]*Node, error) {
 	if n.err != nil {
@@ -164,18 +266,27 @@ func (n *Node) AsObject() (map[string]*Node, error) {
 	return nil, errors.New("node is not a object")
 }
 
-// Unmarshal unmarshals the Node's value into the specified variable.
-func (n *Node) Unmarshal(v any) error {
+// Unmarshal unmarshals the Node's value into the specified variable, following the rules of json.Unmarshal.
+// The value is decoded directly, without marshalling it to JSON first.
+// Errors are reported at the path of the value that could not be decoded.
+func (n *Node) Unmarshal(v any, opts ...UnmarshalOption) error {
 	if n.err != nil {
 		return n.err
 	}
 
-	data, err := marshal(n.path, n.value)
-	if err != nil {
-		return err
+	cfg := unmarshalConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
 	}
 
-	return unmarshal(n.path, data, v)
+	err := decodeValue(n.path, n.value, v, cfg)
+	var nodeErr *NodeError
+	if n.positions != nil && errors.As(err, &nodeErr) {
+		if s, ok := n.positions.lookup(nodeErr.path); ok {
+			nodeErr.pos = n.positions.position(s.start)
+		}
+	}
+	return err
 }
 
 // Marshal marshals the Node's value into JSON format.
@@ -187,10 +298,28 @@ func (n *Node) Marshal() ([]byte, error) {
 	return marshal(n.path, n.value)
 }
 
+var (
+	_ json.Marshaler   = Node{}
+	_ json.Unmarshaler = (*Node)(nil)
+)
+
+// MarshalJSON implements json.Marshaler, so that a Node can be embedded in other values passed to json.Marshal.
+// A Node with an error fails to marshal with the Node's error.
+func (n Node) MarshalJSON() ([]byte, error) {
+	return n.Marshal()
+}
+
+// UnmarshalJSON implements json.Unmarshaler, so that a Node can be used as a field decoded by json.Unmarshal.
+// The Node is replaced by a new root Node representing the given JSON data.
+func (n *Node) UnmarshalJSON(data []byte) error {
+	*n = *Parse(data)
+	return n.err
+}
+
 // UnmarshalNode is a helper function to unmarshal a Node's value into a specified type.
-func UnmarshalNode[T any](node *Node) (T, error) {
+func UnmarshalNode[T any](node *Node, opts ...UnmarshalOption) (T, error) {
 	var v = *new(T)
-	err := node.Unmarshal(&v)
+	err := node.Unmarshal(&v, opts...)
 	return v, err
 }
 
@@ -203,6 +332,18 @@ func (n *Node) Set(value any, props ...any) *Node {
 		return n
 	}
 
+	if n.results != nil {
+		return n.withError(newSelectorSetError(n.path))
+	}
+	if i := selectorIndex(props); i >= 0 {
+		// report the error at the node the Selector applies to
+		selected := n.Get(props[:i]...)
+		if selected.err != nil {
+			return selected
+		}
+		return selected.withError(newSelectorSetError(selected.path))
+	}
+
 	if len(props) == 0 {
 		return n.replaceValue(value)
 	}
@@ -234,6 +375,189 @@ func (n *Node) Set(value any, props ...any) *Node {
 	return targetNode.newParent(len(props))
 }
 
+// CreatePolicy decides what SetCreate does with a null or scalar value in the place of a container it needs.
+type CreatePolicy int
+
+const (
+	// KeepValues reports an error for a null or scalar value in the way.
+	KeepValues CreatePolicy = iota
+	// ReplaceNull replaces a null value with a container, and reports an error for a scalar value in the way.
+	ReplaceNull
+	// ReplaceScalars replaces a null or scalar value with a container.
+	ReplaceScalars
+)
+
+// SetCreate sets the value at the given property path like Set, but creates the missing containers along the path,
+// like mkdir -p. A missing container becomes an array if the next property is an index, and an object if it is a key.
+// A new array is filled with null up to the index.
+// An array or object of the other kind in the way is never replaced, and null or scalar values are replaced
+// according to the policy. Otherwise codeCreatePopertyError is reported at the property that could not be created.
+func (n *Node) SetCreate(value any, policy CreatePolicy, props ...any) *Node {
+	if n.err != nil && !n.IsUndefined() {
+		return n
+	}
+
+	if n.results != nil {
+		return n.withError(newSelectorSetError(n.path))
+	}
+	if i := selectorIndex(props); i >= 0 {
+		selected := n.Get(props[:i]...)
+		if selected.err != nil {
+			return selected
+		}
+		return selected.withError(newSelectorSetError(selected.path))
+	}
+
+	if len(props) == 0 {
+		return n.replaceValue(value)
+	}
+
+	current := n
+	for i, prop := range props {
+		validProp, err := getProperty(prop)
+		if err != nil {
+			panic(fmt.Sprintf("invalid property. prop=%v, type=%T", prop, prop))
+		}
+
+		current = current.createContainer(validProp, policy)
+		if current.err != nil {
+			return current
+		}
+		if i == len(props)-1 {
+			break
+		}
+		current = current.Get(prop)
+	}
+
+	targetNode := current.Set(value, props[len(props)-1])
+	if targetNode.err != nil {
+		return targetNode
+	}
+	return targetNode.newParent(len(props) - 1)
+}
+
+// createContainer returns n if its value is a container that prop can be set in.
+// Otherwise it returns a Node replacing n with an empty container, or a Node with codeCreatePopertyError if the policy does not allow it.
+func (n *Node) createContainer(prop property, policy CreatePolicy) *Node {
+	var container jsonvalue
+	switch prop.(type) {
+	case arrayIndex:
+		if _, ok := n.value.([]any); ok && n.err == nil {
+			return n
+		}
+		container = []any{}
+	case objectKey:
+		if _, ok := n.value.(map[string]any); ok && n.err == nil {
+			return n
+		}
+		container = map[string]any{}
+	default:
+		panic(fmt.Sprintf("invalid property. prop=%v, type=%T", prop, prop))
+	}
+
+	switch n.value.(type) {
+	case []any, map[string]any:
+		// a container of the other kind is never replaced
+	default:
+		switch {
+		case n.IsUndefined(),
+			n.value == nil && policy >= ReplaceNull,
+			policy >= ReplaceScalars:
+			return &Node{
+				parent:    n.parent,
+				value:     container,
+				path:      n.path,
+				positions: n.positions,
+			}
+		}
+	}
+
+	return n.newChild(nil, prop, newCreatePopertyError(n.path.append(prop), n.value))
+}
+
+// Delete removes the value at the given property path within the JSON structure.
+// Removing an array element shifts the following elements down by one.
+// Deleting a property that does not exist returns the Node unchanged.
+// If no properties are provided, it returns an undefined Node.
+// If an error occurs during the operation, it returns a new Node with the error.
+func (n *Node) Delete(props ...any) *Node {
+	if n.err != nil {
+		return n
+	}
+
+	if n.results != nil {
+		return n.withError(newSelectorSetError(n.path))
+	}
+	if i := selectorIndex(props); i >= 0 {
+		// report the error at the node the Selector applies to
+		selected := n.Get(props[:i]...)
+		if selected.err != nil {
+			return selected
+		}
+		return selected.withError(newSelectorSetError(selected.path))
+	}
+
+	if len(props) == 0 {
+		return n.withError(newUndefined(n.path))
+	}
+
+	targetNode := n.Get(props...)
+	if targetNode.IsUndefined() {
+		return n
+	}
+	if targetNode.err != nil {
+		if nodeErr, ok := targetNode.err.(*NodeError); ok {
+			isErrInTargetNode := len(n.path)+len(props) == len(targetNode.path)
+
+			if nodeErr.code == codeReadUndefinedError && isErrInTargetNode {
+				targetNode.err = newDeleteUndefinedError(targetNode.path)
+				return targetNode
+			}
+
+			if nodeErr.code == codeReadNullError && isErrInTargetNode {
+				targetNode.err = newDeleteNullError(targetNode.path)
+				return targetNode
+			}
+		}
+		return targetNode
+	}
+
+	return targetNode.parent.
+		deleteValue(targetNode.path[len(targetNode.path)-1]).
+		newParent(len(props) - 1)
+}
+
+// deleteValue returns a copy of the Node without the value at prop, which must exist.
+func (n *Node) deleteValue(prop property) *Node {
+	var newValue jsonvalue
+	switch typedProp := prop.(type) {
+	case arrayIndex:
+		array := n.value.([]any)
+		newArray := make([]any, 0, len(array)-1)
+		newArray = append(newArray, array[:typedProp]...)
+		newValue = append(newArray, array[typedProp+1:]...)
+
+	case objectKey:
+		newObject := map[string]any{}
+		for k, v := range n.value.(map[string]any) {
+			if k != string(typedProp) {
+				newObject[k] = v
+			}
+		}
+		newValue = newObject
+
+	default:
+		panic(fmt.Sprintf("invalid property. prop=%v, type=%t", prop, prop))
+	}
+
+	return &Node{
+		parent: n.parent,
+		value:  newValue,
+		path:   n.path,
+		err:    nil,
+	}
+}
+
 func (n *Node) replaceValue(value any) *Node {
 	jvalue, err := getJSONValue(value)
 	if err != nil {
@@ -241,7 +565,7 @@ func (n *Node) replaceValue(value any) *Node {
 			parent: n.parent,
 			value:  n.value,
 			path:   n.path,
-			err:    newInternalError(n.path, err),
+			err:    newMarshalError(n.path, err),
 		}
 	}
 
@@ -261,8 +585,15 @@ func (n *Node) setArrayElement(v jsonvalue, idx arrayIndex) *Node {
 		)
 	}
 
+	// negative indexes within the array are resolved by Get, so the remaining ones are out of range.
+	if idx < 0 {
+		return n.newChild(nil, idx,
+			newIndexOutOfRangeError(n.path.append(idx), len(array)),
+		)
+	}
+
 	newLen := len(array)
-	if int(idx) > len(array) {
+	if int(idx) >= len(array) {
 		newLen = int(idx) + 1
 	}
 
@@ -343,6 +674,13 @@ func (n *Node) newParent(depth int) *Node {
 func unmarshal(path jsonpath, data []byte, v jsonvalue) error {
 	err := json.Unmarshal(data, v)
 	if err != nil {
+		// report data after a valid top-level value with its own error
+		dec := json.NewDecoder(bytes.NewReader(data))
+		if dec.Decode(new(json.RawMessage)) == nil {
+			if trailingErr := checkTrailingData(path, data, int(dec.InputOffset())); trailingErr != nil {
+				return trailingErr
+			}
+		}
 		return &NodeError{
 			code: codeUnmarshalError,
 			path: path,
@@ -352,6 +690,22 @@ func unmarshal(path jsonpath, data []byte, v jsonvalue) error {
 	return nil
 }
 
+// unmarshalUseNumber is like unmarshal, but decodes JSON numbers into json.Number.
+func unmarshalUseNumber(path jsonpath, data []byte, v jsonvalue) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	if err := dec.Decode(v); err != nil {
+		return &NodeError{
+			code: codeUnmarshalError,
+			path: path,
+			err:  err,
+		}
+	}
+	// json.Unmarshal rejects anything after the top-level value, and so do we.
+	return checkTrailingData(path, data, int(dec.InputOffset()))
+}
+
 func marshal(path jsonpath, v jsonvalue) ([]byte, error) {
 	data, err := json.Marshal(v)
 	if err != nil {
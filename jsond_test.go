@@ -0,0 +1,238 @@
+package jsond
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNodeMarshalJSON(t *testing.T) {
+	type wrapper struct {
+		Ptr   *Node `json:"ptr"`
+		Value Node  `json:"value"`
+	}
+
+	root := Parse([]byte(`{"a":[1,2]}`))
+
+	t.Run("set nested", func(t *testing.T) {
+		got, err := Parse([]byte(`{}`)).
+			Set(wrapper{Ptr: root.Get("a"), Value: *root.Get("a", 0)}, "w").
+			Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := `{"w":{"ptr":[1,2],"value":1}}`
+		if string(got) != want {
+			t.Errorf("\ngot  %s\nwant %s", got, want)
+		}
+	})
+
+	t.Run("unmarshal", func(t *testing.T) {
+		var w wrapper
+		if err := json.Unmarshal([]byte(`{"ptr":{"b":true},"value":null}`), &w); err != nil {
+			t.Fatal(err)
+		}
+
+		var b bool
+		if err := w.Ptr.Get("b").Unmarshal(&b); err != nil || !b {
+			t.Errorf("\ngot  %v, %v\nwant true", b, err)
+		}
+		if w.Value.err != nil || w.Value.value != nil {
+			t.Errorf("\ngot  %v, %v\nwant null", w.Value.value, w.Value.err)
+		}
+	})
+
+	t.Run("error node", func(t *testing.T) {
+		_, err := json.Marshal(wrapper{Ptr: root.Get("x")})
+		if !IsUndefined(err) {
+			t.Errorf("\ngot  %v\nwant undefined", err)
+		}
+	})
+}
+
+func TestGetArrayIndex(t *testing.T) {
+	root := Parse([]byte(`{"items":["a","b","c"]}`))
+
+	tests := []struct {
+		idx      int
+		want     string
+		wantPath string
+	}{
+		{idx: 0, want: `"a"`, wantPath: "$['items'][0]"},
+		{idx: 2, want: `"c"`, wantPath: "$['items'][2]"},
+		{idx: -1, want: `"c"`, wantPath: "$['items'][2]"},
+		{idx: -3, want: `"a"`, wantPath: "$['items'][0]"},
+		{idx: 3, want: "undefined", wantPath: "$['items'][3]"},
+		{idx: -4, want: "undefined", wantPath: "$['items'][-4]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.idx), func(t *testing.T) {
+			node := root.Get("items", tt.idx)
+
+			got, err := node.Marshal()
+			if err != nil {
+				got = []byte(err.Error())
+			}
+			if string(got) != tt.want {
+				t.Errorf("\ngot  %s\nwant %s", got, tt.want)
+			}
+			if node.path.String() != tt.wantPath {
+				t.Errorf("\ngot  %s\nwant %s", node.path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestGetOptional(t *testing.T) {
+	root := Parse([]byte(`{"user":{"name":"Alice","address":null,"tags":["a"]},"posts":[{"title":"x"},{"title":null}]}`))
+
+	tests := []struct {
+		name     string
+		node     *Node
+		want     string
+		wantPath string
+	}{
+		{name: "existing", node: root.GetOptional("user", "name"), want: `"Alice"`, wantPath: "$['user']['name']"},
+		{name: "null value", node: root.GetOptional("user", "address"), want: "null", wantPath: "$['user']['address']"},
+		{name: "through null", node: root.GetOptional("user", "address", "city", "zip"), want: "undefined", wantPath: "$['user']['address']['city']['zip']"},
+		{name: "through undefined", node: root.GetOptional("account", "id", 0), want: "undefined", wantPath: "$['account']['id'][0]"},
+		{name: "through string", node: root.GetOptional("user", "name", "first"), want: "undefined", wantPath: "$['user']['name']['first']"},
+		{name: "out of range", node: root.GetOptional("user", "tags", 3, "label"), want: "undefined", wantPath: "$['user']['tags'][3]['label']"},
+		{name: "from undefined", node: root.Get("missing").GetOptional("a"), want: "undefined", wantPath: "$['missing']['a']"},
+		{name: "selector", node: root.GetOptional("posts", All, "title"), want: `["x",null]`, wantPath: "$['posts']"},
+		{name: "error", node: Parse([]byte(`{`)).GetOptional("a"), want: "unexpected end of JSON input", wantPath: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.node.Marshal()
+			if err != nil {
+				got = []byte(err.Error())
+			}
+			if string(got) != tt.want {
+				t.Errorf("\ngot  %s\nwant %s", got, tt.want)
+			}
+			if tt.node.path.String() != tt.wantPath {
+				t.Errorf("\ngot  %s\nwant %s", tt.node.path, tt.wantPath)
+			}
+			if tt.want == "undefined" {
+				var undefined *Undefined
+				if !errors.As(tt.node.Error(), &undefined) || undefined.path.String() != tt.wantPath {
+					t.Errorf("\ngot  %#v\nwant undefined at %s", tt.node.Error(), tt.wantPath)
+				}
+			}
+		})
+	}
+}
+
+func TestSetArrayIndex(t *testing.T) {
+	root := Parse([]byte(`{"items":["a","b","c"]}`))
+
+	tests := []struct {
+		idx  int
+		want string
+	}{
+		{idx: -1, want: `{"items":["a","b","x"]}`},
+		{idx: -3, want: `{"items":["x","b","c"]}`},
+		{idx: 3, want: `{"items":["a","b","c","x"]}`},
+		{idx: -4, want: "index -4 out of range for array of length 3 at $['items'][-4]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.idx), func(t *testing.T) {
+			got, err := root.Set("x", "items", tt.idx).Marshal()
+			if err != nil {
+				got = []byte(err.Error())
+			}
+			if string(got) != tt.want {
+				t.Errorf("\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetCreate(t *testing.T) {
+	root := Parse([]byte(`{"a": {"b": 1}, "list": [0], "n": null, "s": "str"}`))
+
+	tests := []struct {
+		name   string
+		policy CreatePolicy
+		props  []any
+		want   string
+	}{
+		{name: "existing path", props: []any{"a", "b"}, want: `{"a":{"b":"x"},"list":[0],"n":null,"s":"str"}`},
+		{name: "missing objects", props: []any{"a", "c", "d"}, want: `{"a":{"b":1,"c":{"d":"x"}},"list":[0],"n":null,"s":"str"}`},
+		{name: "missing array", props: []any{"x", 2, "y"}, want: `{"a":{"b":1},"list":[0],"n":null,"s":"str","x":[null,null,{"y":"x"}]}`},
+		{name: "growing array", props: []any{"list", 2, 0}, want: `{"a":{"b":1},"list":[0,null,["x"]],"n":null,"s":"str"}`},
+		{name: "null kept", props: []any{"n", "y"}, want: "cannot create property 'y' on null at $['n']['y']"},
+		{name: "null replaced", policy: ReplaceNull, props: []any{"n", "y"}, want: `{"a":{"b":1},"list":[0],"n":{"y":"x"},"s":"str"}`},
+		{name: "scalar kept", policy: ReplaceNull, props: []any{"a", "b", "c"}, want: "cannot create property 'c' on number '1' at $['a']['b']['c']"},
+		{name: "scalar replaced", policy: ReplaceScalars, props: []any{"s", 0}, want: `{"a":{"b":1},"list":[0],"n":null,"s":["x"]}`},
+		{name: "other container", policy: ReplaceScalars, props: []any{"list", "y"}, want: "cannot create property 'y' on array '[0]' at $['list']['y']"},
+		{name: "negative index", props: []any{"x", -1, "y"}, want: "index -1 out of range for array of length 0 at $['x'][-1]"},
+		{name: "selector", props: []any{"a", All, "b"}, want: "cannot set values through a selector at $['a']"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := root.SetCreate("x", tt.policy, tt.props...).Marshal()
+			if err != nil {
+				got = []byte(err.Error())
+			}
+			if string(got) != tt.want {
+				t.Errorf("\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+
+	if got, _ := root.Get("missing").SetCreate(1, KeepValues, "a", 0).Marshal(); string(got) != `{"a":[1]}` {
+		t.Errorf("\ngot  %s\nwant %s", got, `{"a":[1]}`)
+	}
+	if got, _ := root.Marshal(); string(got) != `{"a":{"b":1},"list":[0],"n":null,"s":"str"}` {
+		t.Errorf("root was modified: %s", got)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	root := Parse([]byte(`{"a": {"b": 1, "c": [1, 2, 3]}, "n": null, "s": "str"}`))
+
+	tests := []struct {
+		name  string
+		props []any
+		want  string
+	}{
+		{name: "object member", props: []any{"a", "b"}, want: `{"a":{"c":[1,2,3]},"n":null,"s":"str"}`},
+		{name: "array element", props: []any{"a", "c", 1}, want: `{"a":{"b":1,"c":[1,3]},"n":null,"s":"str"}`},
+		{name: "negative index", props: []any{"a", "c", -1}, want: `{"a":{"b":1,"c":[1,2]},"n":null,"s":"str"}`},
+		{name: "missing member", props: []any{"a", "x"}, want: `{"a":{"b":1,"c":[1,2,3]},"n":null,"s":"str"}`},
+		{name: "index out of range", props: []any{"a", "c", 3}, want: `{"a":{"b":1,"c":[1,2,3]},"n":null,"s":"str"}`},
+		{name: "property of a string", props: []any{"s", "x"}, want: `{"a":{"b":1,"c":[1,2,3]},"n":null,"s":"str"}`},
+		{name: "property of null", props: []any{"n", "x"}, want: "cannot delete properties of null (deleting 'x') at $['n']['x']"},
+		{name: "property of undefined", props: []any{"x", "y"}, want: "cannot delete properties of undefined (deleting 'y') at $['x']['y']"},
+		{name: "through undefined", props: []any{"x", "y", "z"}, want: "cannot read properties of undefined (reading 'y') at $['x']['y']"},
+		{name: "selector", props: []any{"a", All}, want: "cannot set values through a selector at $['a']"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := root.Delete(tt.props...).Marshal()
+			if err != nil {
+				got = []byte(err.Error())
+			}
+			if string(got) != tt.want {
+				t.Errorf("\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+
+	// deleting from a child node returns the updated child
+	if got, _ := root.Get("a").Delete("c").Marshal(); string(got) != `{"b":1}` {
+		t.Errorf("\ngot  %s\nwant %s", got, `{"b":1}`)
+	}
+	if got, _ := root.Marshal(); string(got) != `{"a":{"b":1,"c":[1,2,3]},"n":null,"s":"str"}` {
+		t.Errorf("root was modified: %s", got)
+	}
+}
@@ -0,0 +1,178 @@
+package jsond
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Kind is the kind of a JSON value, or of a Node that has no value.
+type Kind int
+
+const (
+	KindNull Kind = iota
+	KindBool
+	KindNumber
+	KindString
+	KindArray
+	KindObject
+	KindUndefined // the Node is undefined
+	KindError     // the Node has an error other than an undefined value
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNull:
+		return "null"
+	case KindBool:
+		return "bool"
+	case KindNumber:
+		return "number"
+	case KindString:
+		return "string"
+	case KindArray:
+		return "array"
+	case KindObject:
+		return "object"
+	case KindUndefined:
+		return "undefined"
+	case KindError:
+		return "error"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// kindOf returns the kind of the jsonvalue v.
+func kindOf(v jsonvalue) Kind {
+	switch v.(type) {
+	case nil:
+		return KindNull
+	case bool:
+		return KindBool
+	case float64, json.Number, int64, uint64:
+		return KindNumber
+	case string:
+		return KindString
+	case []any:
+		return KindArray
+	case map[string]any:
+		return KindObject
+	default:
+		panic(fmt.Sprintf("invalid jsonvalue. v=%v", v))
+	}
+}
+
+// Kind returns the kind of the Node's value. A result set is an array.
+func (n *Node) Kind() Kind {
+	if n.IsUndefined() {
+		return KindUndefined
+	}
+	if n.err != nil {
+		return KindError
+	}
+	return kindOf(n.value)
+}
+
+// IsNull reports whether the Node's value is null.
+func (n *Node) IsNull() bool {
+	return n.Kind() == KindNull
+}
+
+// IsBool reports whether the Node's value is a boolean.
+func (n *Node) IsBool() bool {
+	return n.Kind() == KindBool
+}
+
+// IsNumber reports whether the Node's value is a number.
+func (n *Node) IsNumber() bool {
+	return n.Kind() == KindNumber
+}
+
+// IsString reports whether the Node's value is a string.
+func (n *Node) IsString() bool {
+	return n.Kind() == KindString
+}
+
+// IsArray reports whether the Node's value is an array.
+func (n *Node) IsArray() bool {
+	return n.Kind() == KindArray
+}
+
+// IsObject reports whether the Node's value is an object.
+func (n *Node) IsObject() bool {
+	return n.Kind() == KindObject
+}
+
+// Len returns the number of elements of an array, or the number of members of an object.
+// It returns 0 for other values, and for Nodes with an error.
+func (n *Node) Len() int {
+	if n.err != nil {
+		return 0
+	}
+
+	switch t := n.value.(type) {
+	case []any:
+		return len(t)
+	case map[string]any:
+		return len(t)
+	default:
+		return 0
+	}
+}
+
+// Keys returns the keys of an object in sorted order.
+// It returns nil for other values, and for Nodes with an error.
+func (n *Node) Keys() []string {
+	object, ok := n.value.(map[string]any)
+	if !ok || n.err != nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(object))
+	for k := range object {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// OrderedKeys returns the keys of an object in the order they appear in the parsed input.
+// The order is only known for Nodes parsed with WithPositions. Other keys, such as the ones added by Set,
+// follow in sorted order.
+// It returns nil for values other than objects, and for Nodes with an error.
+func (n *Node) OrderedKeys() []string {
+	keys := n.Keys()
+	if n.positions == nil || len(keys) == 0 {
+		return keys
+	}
+
+	offset := func(k string) int {
+		s, ok := n.positions.lookup(n.path.append(objectKey(k)))
+		if !ok || s.key < 0 {
+			return -1
+		}
+		return s.key
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		oi, oj := offset(keys[i]), offset(keys[j])
+		if oi < 0 || oj < 0 {
+			return oi >= 0 && oj < 0
+		}
+		return oi < oj
+	})
+	return keys
+}
+
+// Has reports whether a value exists at the given property path, as Get would return it without an error.
+// For a path with a Selector, it reports whether anything is selected.
+func (n *Node) Has(props ...any) bool {
+	node := n.Get(props...)
+	if node.err != nil {
+		return false
+	}
+	if node.results != nil {
+		return len(node.results) > 0
+	}
+	return true
+}
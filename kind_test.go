@@ -0,0 +1,104 @@
+package jsond
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKind(t *testing.T) {
+	root := Parse([]byte(`{"n": null, "b": true, "num": 1, "s": "str", "a": [1, 2], "o": {"x": 1}}`))
+
+	tests := []struct {
+		node *Node
+		want Kind
+	}{
+		{node: root.Get("n"), want: KindNull},
+		{node: root.Get("b"), want: KindBool},
+		{node: root.Get("num"), want: KindNumber},
+		{node: Parse([]byte(`1`), UseNumber()), want: KindNumber},
+		{node: root.Set(int64(1), "num").Get("num"), want: KindNumber},
+		{node: root.Get("s"), want: KindString},
+		{node: root.Get("a"), want: KindArray},
+		{node: root.Get("o"), want: KindObject},
+		{node: root.Get("a", All), want: KindArray},
+		{node: root.Get("missing"), want: KindUndefined},
+		{node: root.Get("missing", "x"), want: KindError},
+		{node: Parse([]byte(`{`)), want: KindError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want.String(), func(t *testing.T) {
+			if got := tt.node.Kind(); got != tt.want {
+				t.Errorf("\ngot  %v\nwant %v", got, tt.want)
+			}
+
+			is := map[Kind]bool{
+				KindNull:      tt.node.IsNull(),
+				KindBool:      tt.node.IsBool(),
+				KindNumber:    tt.node.IsNumber(),
+				KindString:    tt.node.IsString(),
+				KindArray:     tt.node.IsArray(),
+				KindObject:    tt.node.IsObject(),
+				KindUndefined: tt.node.IsUndefined(),
+			}
+			for kind, got := range is {
+				if got != (kind == tt.want) {
+					t.Errorf("Is%v: got %v", kind, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLenKeysHas(t *testing.T) {
+	root := Parse([]byte(`{"z": 1, "a": [1, 2, 3], "m": {}, "s": "str", "n": null}`), WithPositions(""))
+
+	lens := map[string]int{"": 5, "a": 3, "m": 0, "s": 0, "n": 0, "missing": 0}
+	for key, want := range lens {
+		node := root
+		if key != "" {
+			node = root.Get(key)
+		}
+		if got := node.Len(); got != want {
+			t.Errorf("Len of %q\ngot  %d\nwant %d", key, got, want)
+		}
+	}
+
+	if got, want := root.Keys(), []string{"a", "m", "n", "s", "z"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %v\nwant %v", got, want)
+	}
+	if got, want := root.OrderedKeys(), []string{"z", "a", "m", "s", "n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %v\nwant %v", got, want)
+	}
+	if got := root.Get("a").Keys(); got != nil {
+		t.Errorf("\ngot  %v\nwant nil", got)
+	}
+	if got := root.Get("m").Keys(); got == nil || len(got) != 0 {
+		t.Errorf("\ngot  %#v\nwant empty", got)
+	}
+
+	// keys added by Set have no position, and the Node returned by Set has no positions at all
+	added := root.Set(1, "b")
+	if got, want := added.OrderedKeys(), []string{"a", "b", "m", "n", "s", "z"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot  %v\nwant %v", got, want)
+	}
+
+	has := []struct {
+		props []any
+		want  bool
+	}{
+		{props: []any{"z"}, want: true},
+		{props: []any{"n"}, want: true},
+		{props: []any{"a", -1}, want: true},
+		{props: []any{"a", 3}, want: false},
+		{props: []any{"missing"}, want: false},
+		{props: []any{"n", "x"}, want: false},
+		{props: []any{"a", All}, want: true},
+		{props: []any{"m", All}, want: false},
+	}
+	for _, tt := range has {
+		if got := root.Has(tt.props...); got != tt.want {
+			t.Errorf("Has%v\ngot  %v\nwant %v", tt.props, got, tt.want)
+		}
+	}
+}
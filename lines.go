@@ -0,0 +1,146 @@
+package jsond
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// LineReader reads a stream of JSON values one Node at a time.
+// It accepts newline-delimited JSON (NDJSON / JSON Lines) as well as
+// JSON values that are simply concatenated or separated by other whitespace.
+type LineReader struct {
+	counter *lineCounter
+	dec     *json.Decoder
+	opts    []ParseOption
+	line    int
+	err     error
+}
+
+// NewLineReader returns a new LineReader that reads from r.
+// The given options are applied when parsing each value.
+func NewLineReader(r io.Reader, opts ...ParseOption) *LineReader {
+	counter := &lineCounter{r: r}
+
+	return &LineReader{
+		counter: counter,
+		dec:     json.NewDecoder(counter),
+		opts:    opts,
+	}
+}
+
+// Read reads the next JSON value from the stream and returns it as a Node.
+// It returns io.EOF when there are no more values.
+// A syntax error is reported as a NodeError with the line number, and ends the stream.
+// A value rejected by the ParseOptions, such as DisallowDuplicateKeys, is reported with the line number
+// wrapping the error of Parse, and reading continues with the next value.
+func (r *LineReader) Read() (*Node, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var raw json.RawMessage
+	if err := r.dec.Decode(&raw); err != nil {
+		if err == io.EOF {
+			r.err = io.EOF
+			return nil, r.err
+		}
+
+		// report the line of the offending character, or of the start of the incomplete value.
+		offset := r.counter.skipSpace(r.dec.InputOffset())
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			offset = syntaxErr.Offset - 1
+		}
+		r.line = r.counter.lineAt(offset)
+
+		r.err = &NodeError{
+			code: codeUnmarshalError,
+			path: jsonpath{},
+			err:  fmt.Errorf("line %d: %w", r.line, err),
+		}
+		return nil, r.err
+	}
+
+	start := r.dec.InputOffset() - int64(len(raw))
+	r.line = r.counter.lineAt(start)
+
+	node := Parse(raw, r.opts...)
+	if node.err != nil {
+		return nil, fmt.Errorf("line %d: %w", r.line, node.err)
+	}
+	return node, nil
+}
+
+// Line returns the line number on which the value last read by Read starts.
+func (r *LineReader) Line() int {
+	return r.line
+}
+
+// lineCounter is an io.Reader that keeps the bytes read through it until their line numbers are counted.
+type lineCounter struct {
+	r      io.Reader
+	buf    []byte // bytes read but not yet counted
+	offset int64  // input offset of buf[0]
+	lines  int    // number of newlines before offset
+}
+
+func (c *lineCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.buf = append(c.buf, p[:n]...)
+	return n, err
+}
+
+// lineAt returns the 1-based line number of the given input offset.
+// Offsets passed to successive calls must not decrease.
+func (c *lineCounter) lineAt(offset int64) int {
+	n := int(offset - c.offset)
+	if n > len(c.buf) {
+		n = len(c.buf)
+	}
+	if n > 0 {
+		c.lines += bytes.Count(c.buf[:n], []byte{'\n'})
+		c.buf = append(c.buf[:0], c.buf[n:]...)
+		c.offset += int64(n)
+	}
+	return c.lines + 1
+}
+
+// skipSpace returns the offset of the first non-whitespace byte at or after the given input offset.
+func (c *lineCounter) skipSpace(offset int64) int64 {
+	for i := int(offset - c.offset); i >= 0 && i < len(c.buf); i++ {
+		switch c.buf[i] {
+		case ' ', '\t', '\r', '\n':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// LineWriter writes Nodes as newline-delimited JSON (NDJSON / JSON Lines).
+type LineWriter struct {
+	w io.Writer
+}
+
+// NewLineWriter returns a new LineWriter that writes to w.
+func NewLineWriter(w io.Writer) *LineWriter {
+	return &LineWriter{w: w}
+}
+
+// Write writes the Node's value as a single line of JSON followed by a newline.
+// A Node with an error is not written, and its error is returned.
+func (w *LineWriter) Write(n *Node) error {
+	data, err := n.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.w.Write(append(data, '\n')); err != nil {
+		return newInternalError(n.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package jsond
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLineReader(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		src       string
+		wantLines []int
+		wantErr   string
+	}{
+		{
+			name:      "ndjson",
+			src:       "{\"a\":1}\n{\"a\":2}\n\n{\"a\":3}\n",
+			wantLines: []int{1, 2, 4},
+		},
+		{
+			name:      "concatenated",
+			src:       "{\"a\":1}{\"a\":2} 3\n[\n4\n]",
+			wantLines: []int{1, 1, 1, 2},
+		},
+		{
+			name:      "syntax error",
+			src:       "{\"a\":1}\n{\"a\":2}\n{\"a\": x}\n{\"a\":4}\n",
+			wantLines: []int{1, 2},
+			wantErr:   "line 3: invalid character 'x' looking for beginning of value",
+		},
+		{
+			name:      "unexpected eof",
+			src:       "{\"a\":1}\n{\"a\":\n",
+			wantLines: []int{1},
+			wantErr:   "line 2: unexpected EOF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewLineReader(strings.NewReader(tt.src))
+
+			gotLines := []int{}
+			var gotErr error
+			for {
+				_, err := r.Read()
+				if err != nil {
+					if err != io.EOF {
+						gotErr = err
+					}
+					break
+				}
+				gotLines = append(gotLines, r.Line())
+			}
+
+			if len(gotLines) != len(tt.wantLines) {
+				t.Fatalf("\ngot  %v\nwant %v", gotLines, tt.wantLines)
+			}
+			for i := range gotLines {
+				if gotLines[i] != tt.wantLines[i] {
+					t.Errorf("\ngot  %v\nwant %v", gotLines, tt.wantLines)
+				}
+			}
+
+			if tt.wantErr == "" {
+				if gotErr != nil {
+					t.Errorf("unexpected error: %v", gotErr)
+				}
+				return
+			}
+			if gotErr == nil || gotErr.Error() != tt.wantErr {
+				t.Errorf("\ngot  %v\nwant %s", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLineReaderParseOptions(t *testing.T) {
+	src := "{\"a\":1}\n{\"a\":1,\"a\":2}\n{\"a\":3}\n"
+	r := NewLineReader(strings.NewReader(src), DisallowDuplicateKeys())
+
+	if _, err := r.Read(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := r.Read()
+	want := `line 2: line 1, column 8: duplicate object key "a" at $['a']`
+	if err == nil || err.Error() != want {
+		t.Fatalf("\ngot  %v\nwant %s", err, want)
+	}
+	if !errors.Is(err, ErrDuplicateKey) || r.Line() != 2 {
+		t.Errorf("got %v at line %d", err, r.Line())
+	}
+
+	// reading continues after the rejected value
+	node, err := r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := UnmarshalNode[int](node.Get("a")); got != 3 || r.Line() != 3 {
+		t.Errorf("got %d at line %d", got, r.Line())
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("\ngot  %v\nwant EOF", err)
+	}
+}
+
+func TestLineWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewLineWriter(buf)
+
+	root := Parse([]byte(`{"items":[{"id":1},{"id":2}]}`))
+	for _, n := range []*Node{root.Get("items", 0), root.Get("items", 1)} {
+		if err := w.Write(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := "{\"id\":1}\n{\"id\":2}\n"
+	if buf.String() != want {
+		t.Errorf("\ngot  %q\nwant %q", buf.String(), want)
+	}
+
+	if err := w.Write(root.Get("x")); !IsUndefined(err) {
+		t.Errorf("\ngot  %v\nwant undefined", err)
+	}
+}
@@ -0,0 +1,455 @@
+package jsond
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// msgpackTimestamp is the extension type of the predefined timestamp extension.
+const msgpackTimestamp int8 = -1
+
+// 8, 16 and 32 bit length formats of strings, arrays and maps
+var (
+	msgpackStr   = [3]byte{0xd9, 0xda, 0xdb}
+	msgpackArray = [3]byte{0, 0xdc, 0xdd}
+	msgpackMap   = [3]byte{0, 0xde, 0xdf}
+)
+
+// MsgpackOption configures how ParseMsgpack decodes MessagePack data.
+type MsgpackOption func(*msgpackConfig)
+
+type msgpackConfig struct {
+	ext map[int8]func(data []byte) (any, error)
+}
+
+// MsgpackExt registers the function that converts the payload of the extension type typ to a JSON value.
+// It replaces the default conversion of the timestamp extension (type -1), which produces an RFC 3339 string in UTC.
+func MsgpackExt(typ int8, f func(data []byte) (any, error)) MsgpackOption {
+	return func(c *msgpackConfig) {
+		c.ext[typ] = f
+	}
+}
+
+// ParseMsgpack decodes a single MessagePack object and returns a Node representing its structure.
+//
+// Integers are decoded as int64 (or uint64 if they do not fit) and floats as float64.
+// Binary data becomes a base64 string, and extension types are converted by the functions registered with MsgpackExt.
+// Map keys must be strings or integers.
+func ParseMsgpack(data []byte, opts ...MsgpackOption) *Node {
+	cfg := msgpackConfig{
+		ext: map[int8]func([]byte) (any, error){
+			msgpackTimestamp: decodeMsgpackTimestamp,
+		},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	d := &msgpackDecoder{
+		data: data,
+		cfg:  cfg,
+	}
+	path := jsonpath{}
+
+	value, err := d.decode(path, 0)
+	if err == nil && d.offset < len(d.data) {
+		err = newDecodeError(path, d.offset, "unexpected data after top-level MessagePack object")
+	}
+	if err != nil {
+		value = nil
+	}
+
+	return &Node{
+		parent: nil,
+		value:  value,
+		path:   path,
+		err:    err,
+	}
+}
+
+type msgpackDecoder struct {
+	data   []byte
+	offset int
+	cfg    msgpackConfig
+}
+
+func (d *msgpackDecoder) decode(path jsonpath, depth int) (jsonvalue, error) {
+	if depth > maxParseDepth {
+		return nil, newDecodeError(path, d.offset, "exceeded max depth")
+	}
+
+	start := d.offset
+	b, err := d.read(path, start, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c := b[0]; {
+	case c <= 0x7f: // positive fixint
+		return int64(c), nil
+	case c >= 0xe0: // negative fixint
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80: // fixmap
+		return d.decodeMap(path, depth, start, uint64(c&0x0f))
+	case c&0xf0 == 0x90: // fixarray
+		return d.decodeArray(path, depth, start, uint64(c&0x0f))
+	case c&0xe0 == 0xa0: // fixstr
+		return d.decodeString(path, start, uint64(c&0x1f))
+	}
+
+	switch c := b[0]; c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+
+	case 0xc4, 0xc5, 0xc6: // bin 8, 16, 32
+		n, err := d.readUint(path, start, 1<<(c-0xc4))
+		if err != nil {
+			return nil, err
+		}
+		data, err := d.read(path, start, n)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+
+	case 0xc7, 0xc8, 0xc9: // ext 8, 16, 32
+		n, err := d.readUint(path, start, 1<<(c-0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(path, start, n)
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8: // fixext 1, 2, 4, 8, 16
+		return d.decodeExt(path, start, 1<<(c-0xd4))
+
+	case 0xca: // float 32
+		n, err := d.readUint(path, start, 4)
+		if err != nil {
+			return nil, err
+		}
+		return d.checkFloat(path, start, float64(math.Float32frombits(uint32(n))))
+	case 0xcb: // float 64
+		n, err := d.readUint(path, start, 8)
+		if err != nil {
+			return nil, err
+		}
+		return d.checkFloat(path, start, math.Float64frombits(n))
+
+	case 0xcc, 0xcd, 0xce, 0xcf: // uint 8, 16, 32, 64
+		n, err := d.readUint(path, start, 1<<(c-0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3: // int 8, 16, 32, 64
+		size := 1 << (c - 0xd0)
+		n, err := d.readUint(path, start, size)
+		if err != nil {
+			return nil, err
+		}
+		// sign-extend the big-endian value to 64 bits
+		shift := 64 - 8*size
+		return int64(n<<shift) >> shift, nil
+
+	case 0xd9, 0xda, 0xdb: // str 8, 16, 32
+		n, err := d.readUint(path, start, 1<<(c-0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(path, start, n)
+	case 0xdc, 0xdd: // array 16, 32
+		n, err := d.readUint(path, start, 2<<(c-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(path, depth, start, n)
+	case 0xde, 0xdf: // map 16, 32
+		n, err := d.readUint(path, start, 2<<(c-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(path, depth, start, n)
+
+	default: // 0xc1
+		return nil, newDecodeError(path, start, fmt.Sprintf("invalid format 0x%02x", c))
+	}
+}
+
+func (d *msgpackDecoder) decodeString(path jsonpath, start int, n uint64) (jsonvalue, error) {
+	b, err := d.read(path, start, n)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(b) {
+		return nil, newDecodeError(path, start, "invalid UTF-8 in string")
+	}
+	return string(b), nil
+}
+
+func (d *msgpackDecoder) decodeArray(path jsonpath, depth int, start int, n uint64) (jsonvalue, error) {
+	// every element needs at least one byte, which bounds the length by the remaining input
+	if n > uint64(len(d.data)-d.offset) {
+		return nil, newDecodeError(path, start, "unexpected end of MessagePack input")
+	}
+
+	array := make([]any, 0, n)
+	for i := 0; i < int(n); i++ {
+		v, err := d.decode(path.append(arrayIndex(i)), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, v)
+	}
+	return array, nil
+}
+
+func (d *msgpackDecoder) decodeMap(path jsonpath, depth int, start int, n uint64) (jsonvalue, error) {
+	if n > uint64(len(d.data)-d.offset) {
+		return nil, newDecodeError(path, start, "unexpected end of MessagePack input")
+	}
+
+	object := map[string]any{}
+	for i := 0; i < int(n); i++ {
+		keyStart := d.offset
+		k, err := d.decode(path, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := integerKeyString(k)
+		if !ok {
+			return nil, newDecodeError(path, keyStart, fmt.Sprintf("unsupported map key type %s", getTypeString(k)))
+		}
+		v, err := d.decode(path.append(objectKey(key)), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		object[key] = v
+	}
+	return object, nil
+}
+
+func (d *msgpackDecoder) decodeExt(path jsonpath, start int, n uint64) (jsonvalue, error) {
+	typ, err := d.read(path, start, 1)
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.read(path, start, n)
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := d.cfg.ext[int8(typ[0])]
+	if !ok {
+		return nil, newDecodeError(path, start, fmt.Sprintf("unsupported extension type %d", int8(typ[0])))
+	}
+	v, err := f(data)
+	if err == nil {
+		v, err = getJSONValue(v)
+	}
+	if err != nil {
+		return nil, newDecodeError(path, start, err.Error())
+	}
+	return v, nil
+}
+
+func (d *msgpackDecoder) checkFloat(path jsonpath, start int, f float64) (jsonvalue, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, newDecodeError(path, start, fmt.Sprintf("%v cannot be represented in JSON", f))
+	}
+	return f, nil
+}
+
+// read consumes the next n bytes.
+func (d *msgpackDecoder) read(path jsonpath, start int, n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.offset) {
+		return nil, newDecodeError(path, start, "unexpected end of MessagePack input")
+	}
+	b := d.data[d.offset : d.offset+int(n)]
+	d.offset += int(n)
+	return b, nil
+}
+
+// readUint consumes a big-endian unsigned integer of the given size.
+func (d *msgpackDecoder) readUint(path jsonpath, start int, size int) (uint64, error) {
+	b, err := d.read(path, start, uint64(size))
+	if err != nil {
+		return 0, err
+	}
+
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+// decodeMsgpackTimestamp converts the timestamp extension (timestamp 32, 64 or 96) to an RFC 3339 string.
+func decodeMsgpackTimestamp(data []byte) (any, error) {
+	var sec int64
+	var nsec uint32
+	switch len(data) {
+	case 4:
+		sec = int64(binary.BigEndian.Uint32(data))
+	case 8:
+		n := binary.BigEndian.Uint64(data)
+		sec, nsec = int64(n&(1<<34-1)), uint32(n>>34)
+	case 12:
+		nsec, sec = binary.BigEndian.Uint32(data), int64(binary.BigEndian.Uint64(data[4:]))
+	default:
+		return nil, fmt.Errorf("invalid timestamp length %d", len(data))
+	}
+	if nsec >= 1e9 {
+		return nil, fmt.Errorf("invalid timestamp nanoseconds %d", nsec)
+	}
+	return time.Unix(sec, int64(nsec)).UTC().Format(time.RFC3339Nano), nil
+}
+
+// MarshalMsgpack encodes the Node's value as MessagePack, using the smallest format for each value.
+// Integers (int64, uint64 and integral json.Number values) are encoded as integers and other numbers as float 64.
+// Map keys are sorted so that the output is deterministic.
+func (n *Node) MarshalMsgpack() ([]byte, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeMsgpack(buf, n.path, n.value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeMsgpack(buf *bytes.Buffer, path jsonpath, v jsonvalue) error {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if t {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int64:
+		writeMsgpackInt(buf, t)
+	case uint64:
+		if t > math.MaxInt64 {
+			buf.WriteByte(0xcf)
+			buf.Write(binary.BigEndian.AppendUint64(nil, t))
+		} else {
+			writeMsgpackInt(buf, int64(t))
+		}
+	case float64:
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			return newMarshalError(path, fmt.Errorf("unsupported number: %v", t))
+		}
+		buf.WriteByte(0xcb)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(t)))
+	case json.Number:
+		if i, ok := new(big.Int).SetString(string(t), 10); ok {
+			switch {
+			case i.IsInt64():
+				return writeMsgpack(buf, path, i.Int64())
+			case i.IsUint64():
+				return writeMsgpack(buf, path, i.Uint64())
+			default:
+				return newMarshalError(path, fmt.Errorf("integer %s overflows 64 bits", t))
+			}
+		}
+		f, err := numberFloat64(t)
+		if err != nil {
+			return newMarshalError(path, err)
+		}
+		return writeMsgpack(buf, path, f)
+	case string:
+		writeMsgpackHead(buf, uint64(len(t)), 0xa0, 31, msgpackStr)
+		buf.WriteString(t)
+	case []any:
+		writeMsgpackHead(buf, uint64(len(t)), 0x90, 15, msgpackArray)
+		for i, elem := range t {
+			if err := writeMsgpack(buf, path.append(arrayIndex(i)), elem); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		writeMsgpackHead(buf, uint64(len(t)), 0x80, 15, msgpackMap)
+		for _, k := range keys {
+			writeMsgpackHead(buf, uint64(len(k)), 0xa0, 31, msgpackStr)
+			buf.WriteString(k)
+			if err := writeMsgpack(buf, path.append(objectKey(k)), t[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return newInternalError(path, fmt.Errorf("invalid jsonvalue. v=%v", v))
+	}
+	return nil
+}
+
+// writeMsgpackHead writes the format and length of a string, array or map.
+// It uses the fix format for lengths up to fixMax, and otherwise the first of the 8, 16 and 32 bit formats
+// that can hold the length. A zero format is skipped.
+func writeMsgpackHead(buf *bytes.Buffer, n uint64, fix byte, fixMax uint64, formats [3]byte) {
+	switch {
+	case n <= fixMax:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint8 && formats[0] != 0:
+		buf.Write([]byte{formats[0], byte(n)})
+	case n <= math.MaxUint16:
+		buf.WriteByte(formats[1])
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(formats[2])
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+func writeMsgpackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		buf.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= 0 && i <= math.MaxUint8:
+		buf.Write([]byte{0xcc, byte(i)})
+	case i >= 0 && i <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(i)))
+	case i >= 0 && i <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(i)))
+	case i >= 0:
+		buf.WriteByte(0xcf)
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	case i >= math.MinInt8:
+		buf.Write([]byte{0xd0, byte(i)})
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(i)))
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(i)))
+	default:
+		buf.WriteByte(0xd3)
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	}
+}
@@ -0,0 +1,176 @@
+package jsond
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMsgpack(t *testing.T) {
+
+	tests := []struct {
+		src  string
+		want jsonvalue
+	}{
+		{src: "00", want: int64(0)},
+		{src: "7f", want: int64(127)},
+		{src: "ff", want: int64(-1)},
+		{src: "e0", want: int64(-32)},
+		{src: "cc80", want: int64(128)},
+		{src: "cd0100", want: int64(256)},
+		{src: "ce00010000", want: int64(65536)},
+		{src: "cf001fffffffffffff", want: int64(9007199254740991)},
+		{src: "cf0020000000000001", want: int64(9007199254740993)},
+		{src: "cfffffffffffffffff", want: uint64(18446744073709551615)},
+		{src: "d080", want: int64(-128)},
+		{src: "d1ff7f", want: int64(-129)},
+		{src: "d2ffff7fff", want: int64(-32769)},
+		{src: "d38000000000000000", want: int64(math.MinInt64)},
+		{src: "ca3fc00000", want: 1.5},
+		{src: "cb3ff199999999999a", want: 1.1},
+		{src: "c0", want: nil},
+		{src: "c2", want: false},
+		{src: "c3", want: true},
+		{src: "a3616263", want: "abc"},
+		{src: "d903616263", want: "abc"},
+		{src: "da0003616263", want: "abc"},
+		{src: "c40401020304", want: "AQIDBA=="},
+		{src: "90", want: []any{}},
+		{src: "920102", want: []any{int64(1), int64(2)}},
+		{src: "dc0002a161c0", want: []any{"a", nil}},
+		{src: "82a16101a16292c3c2", want: map[string]any{"a": int64(1), "b": []any{true, false}}},
+		{src: "de000101a178", want: map[string]any{"1": "x"}},
+		{src: "d6ff5a4ec4e0", want: "2018-01-05T00:20:48Z"},
+		{src: "d7ff0000000c5a4ec4e0", want: "2018-01-05T00:20:48.000000003Z"},
+		{src: "c70cff00000001ffffffffffffffff", want: "1969-12-31T23:59:59.000000001Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.src)
+			got := ParseMsgpack(data)
+			if got.err != nil {
+				t.Fatal(got.err)
+			}
+			if !reflect.DeepEqual(got.value, tt.want) {
+				t.Errorf("\ngot  %#v\nwant %#v", got.value, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMsgpackError(t *testing.T) {
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "empty", src: "", want: "offset 0: unexpected end of MessagePack input"},
+		{name: "truncated", src: "81a161cd01", want: "offset 3: unexpected end of MessagePack input at $['a']"},
+		{name: "huge length", src: "ddffffffff", want: "offset 0: unexpected end of MessagePack input"},
+		{name: "trailing data", src: "0101", want: "offset 1: unexpected data after top-level MessagePack object"},
+		{name: "never used", src: "91c1", want: "offset 1: invalid format 0xc1 at $[0]"},
+		{name: "invalid utf-8", src: "9201a1ff", want: "offset 2: invalid UTF-8 in string at $[1]"},
+		{name: "map key", src: "81c301", want: "offset 1: unsupported map key type bool"},
+		{name: "nan", src: "91ca7fc00000", want: "offset 1: NaN cannot be represented in JSON at $[0]"},
+		{name: "unknown ext", src: "91d40501", want: "offset 1: unsupported extension type 5 at $[0]"},
+		{name: "bad timestamp", src: "d5ff0000", want: "offset 0: invalid timestamp length 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.src)
+			err := ParseMsgpack(data).Error()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("\ngot  %v\nwant %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMsgpackExt(t *testing.T) {
+	data, _ := hex.DecodeString("82a2696401a474616773c7050576312c7632")
+	split := MsgpackExt(5, func(data []byte) (any, error) {
+		return strings.Split(string(data), ","), nil
+	})
+
+	got, err := ParseMsgpack(data, split).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":1,"tags":["v1","v2"]}`; string(got) != want {
+		t.Errorf("\ngot  %s\nwant %s", got, want)
+	}
+
+	failing := MsgpackExt(5, func(data []byte) (any, error) {
+		return nil, errors.New("bad tags")
+	})
+	err = ParseMsgpack(data, failing).Error()
+	if want := "offset 10: bad tags at $['tags']"; err == nil || err.Error() != want {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+}
+
+func TestMarshalMsgpack(t *testing.T) {
+
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{src: `0`, want: "00"},
+		{src: `-32`, want: "e0"},
+		{src: `-33`, want: "d0df"},
+		{src: `200`, want: "ccc8"},
+		{src: `-40000`, want: "d2ffff63c0"},
+		{src: `9007199254740993`, want: "cf0020000000000001"},
+		{src: `18446744073709551615`, want: "cfffffffffffffffff"},
+		{src: `1.5`, want: "cb3ff8000000000000"},
+		{src: `null`, want: "c0"},
+		{src: `true`, want: "c3"},
+		{src: `"abc"`, want: "a3616263"},
+		{src: `"` + strings.Repeat("x", 32) + `"`, want: "d920" + strings.Repeat("78", 32)},
+		{src: `{"b": [false], "a": {}}`, want: "82a16180a16291c2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			got, err := Parse([]byte(tt.src), UseNumber()).MarshalMsgpack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("\ngot  %x\nwant %s", got, tt.want)
+			}
+		})
+	}
+
+	_, err := Parse([]byte(`{"a": [18446744073709551616]}`), UseNumber()).MarshalMsgpack()
+	if want := "integer 18446744073709551616 overflows 64 bits at $['a'][0]"; err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+}
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	src := `{"id": 9007199254740993, "name": "web", "ports": [80, 443, -1], "ratio": 0.25, "nested": [{"a": null, "b": [true]}]}`
+
+	data, err := Parse([]byte(src), UseNumber()).MarshalMsgpack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := ParseMsgpack(data)
+	if got := node.Get("id").value; got != int64(9007199254740993) {
+		t.Errorf("\ngot  %#v\nwant %d", got, int64(9007199254740993))
+	}
+	equal, err := Equal(mustMarshal(t, node), []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equal {
+		t.Errorf("round trip changed the value: %x", data)
+	}
+}
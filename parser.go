@@ -0,0 +1,611 @@
+package jsond
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxParseDepth is the maximum nesting depth accepted by the parser, the same as encoding/json.
+const maxParseDepth = 10000
+
+// parser is a hand-written JSON parser used by Parse for the options that encoding/json cannot handle.
+// In relaxed mode, it also accepts the JSONC / JSON5 extensions listed in ParseRelaxed.
+type parser struct {
+	data      []byte
+	offset    int
+	depth     int
+	relaxed   bool
+	useNumber bool
+	positions *positionTable // nil unless positions are recorded
+
+	disallowDuplicateKeys bool
+	disallowInvalidUTF8   bool
+}
+
+func newParser(data []byte, cfg parseConfig) *parser {
+	p := &parser{
+		data:      data,
+		relaxed:   cfg.relaxed,
+		useNumber: cfg.useNumber,
+
+		disallowDuplicateKeys: cfg.disallowDuplicateKeys,
+		disallowInvalidUTF8:   cfg.disallowInvalidUTF8,
+	}
+	if cfg.positions {
+		p.positions = newPositionTable(cfg.filename, data)
+	}
+	return p
+}
+
+// parse parses the whole input as a single JSON value.
+func (p *parser) parse() (jsonvalue, error) {
+	path := jsonpath{}
+
+	v, err := p.parseValue(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.skipSpace(path); err != nil {
+		return nil, err
+	}
+	if p.offset < len(p.data) {
+		return nil, p.trailingDataError(path)
+	}
+	return v, nil
+}
+
+func (p *parser) parseValue(path jsonpath) (jsonvalue, error) {
+	if err := p.skipSpace(path); err != nil {
+		return nil, err
+	}
+	if p.offset >= len(p.data) {
+		return nil, p.errorf(path, p.offset, "unexpected end of JSON input")
+	}
+
+	start := p.offset
+	v, err := p.parseToken(path)
+	if err == nil && p.positions != nil {
+		p.positions.addValue(path, start, p.offset)
+	}
+	return v, err
+}
+
+// parseToken parses the value that starts at the current offset.
+func (p *parser) parseToken(path jsonpath) (jsonvalue, error) {
+	switch c := p.data[p.offset]; {
+	case c == '{':
+		return p.parseObject(path)
+	case c == '[':
+		return p.parseArray(path)
+	case c == '"' || (c == '\'' && p.relaxed):
+		return p.parseString(path)
+	case c == '-' || ('0' <= c && c <= '9') || (p.relaxed && (c == '+' || c == '.')):
+		return p.parseNumber(path)
+	case p.hasLiteral("true"):
+		p.offset += len("true")
+		return true, nil
+	case p.hasLiteral("false"):
+		p.offset += len("false")
+		return false, nil
+	case p.hasLiteral("null"):
+		p.offset += len("null")
+		return nil, nil
+	case p.relaxed && (p.hasLiteral("Infinity") || p.hasLiteral("NaN")):
+		return nil, p.errorf(path, p.offset, "%s cannot be represented in JSON", p.identifierAt(p.offset))
+	default:
+		return nil, p.errorf(path, p.offset, "invalid character %s looking for beginning of value", p.quoteChar())
+	}
+}
+
+func (p *parser) enter(path jsonpath) error {
+	p.depth++
+	if p.depth > maxParseDepth {
+		return p.errorf(path, p.offset, "exceeded max depth")
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) parseObject(path jsonpath) (jsonvalue, error) {
+	if err := p.enter(path); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	p.offset++ // '{'
+	object := map[string]any{}
+
+	for {
+		if err := p.skipSpace(path); err != nil {
+			return nil, err
+		}
+		if p.offset >= len(p.data) {
+			return nil, p.errorf(path, p.offset, "unexpected end of JSON input")
+		}
+		if p.data[p.offset] == '}' && (len(object) == 0 || p.relaxed) {
+			p.offset++
+			return object, nil
+		}
+
+		keyStart := p.offset
+		key, err := p.parseKey(path)
+		if err != nil {
+			return nil, err
+		}
+		elemPath := path.append(objectKey(key))
+		if _, ok := object[key]; ok && p.disallowDuplicateKeys {
+			return nil, p.newError(codeDuplicateKeyError, elemPath, keyStart, fmt.Errorf("%w %q", ErrDuplicateKey, key))
+		}
+		if p.positions != nil {
+			p.positions.addKey(elemPath, keyStart)
+		}
+
+		if err := p.skipSpace(elemPath); err != nil {
+			return nil, err
+		}
+		if err := p.expect(elemPath, ':', "after object key"); err != nil {
+			return nil, err
+		}
+
+		v, err := p.parseValue(elemPath)
+		if err != nil {
+			return nil, err
+		}
+		object[key] = v
+
+		if err := p.skipSpace(path); err != nil {
+			return nil, err
+		}
+		if p.offset < len(p.data) && p.data[p.offset] == '}' {
+			p.offset++
+			return object, nil
+		}
+		if err := p.expect(path, ',', "after object key:value pair"); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseKey(path jsonpath) (string, error) {
+	c := p.data[p.offset]
+
+	if c == '"' || (c == '\'' && p.relaxed) {
+		v, err := p.parseString(path)
+		if err != nil {
+			return "", err
+		}
+		return v.(string), nil
+	}
+
+	if p.relaxed && isIdentifierStart(p.runeAt(p.offset)) {
+		key := p.identifierAt(p.offset)
+		p.offset += len(key)
+		return key, nil
+	}
+
+	return "", p.errorf(path, p.offset, "invalid character %s looking for beginning of object key string", p.quoteChar())
+}
+
+func (p *parser) parseArray(path jsonpath) (jsonvalue, error) {
+	if err := p.enter(path); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	p.offset++ // '['
+	array := []any{}
+
+	for {
+		if err := p.skipSpace(path); err != nil {
+			return nil, err
+		}
+		if p.offset >= len(p.data) {
+			return nil, p.errorf(path, p.offset, "unexpected end of JSON input")
+		}
+		if p.data[p.offset] == ']' && (len(array) == 0 || p.relaxed) {
+			p.offset++
+			return array, nil
+		}
+
+		v, err := p.parseValue(path.append(arrayIndex(len(array))))
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, v)
+
+		if err := p.skipSpace(path); err != nil {
+			return nil, err
+		}
+		if p.offset < len(p.data) && p.data[p.offset] == ']' {
+			p.offset++
+			return array, nil
+		}
+		if err := p.expect(path, ',', "after array element"); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseString(path jsonpath) (jsonvalue, error) {
+	quote := p.data[p.offset]
+	start := p.offset
+	p.offset++
+
+	sb := strings.Builder{}
+	for {
+		if p.offset >= len(p.data) {
+			return nil, p.errorf(path, start, "unterminated string")
+		}
+
+		c := p.data[p.offset]
+		switch {
+		case c == quote:
+			p.offset++
+			return sb.String(), nil
+
+		case c == '\\':
+			if err := p.parseEscape(path, &sb); err != nil {
+				return nil, err
+			}
+
+		case c < 0x20:
+			return nil, p.errorf(path, p.offset, "invalid character %s in string literal", p.quoteChar())
+
+		case c < utf8.RuneSelf:
+			sb.WriteByte(c)
+			p.offset++
+
+		default:
+			r, size := utf8.DecodeRune(p.data[p.offset:])
+			if r == utf8.RuneError && size == 1 && p.disallowInvalidUTF8 {
+				return nil, p.newError(codeInvalidUTF8Error, path, p.offset, fmt.Errorf("%w in string literal", ErrInvalidUTF8))
+			}
+			// otherwise, invalid UTF-8 is replaced with U+FFFD, as encoding/json does.
+			sb.WriteRune(r)
+			p.offset += size
+		}
+	}
+}
+
+func (p *parser) parseEscape(path jsonpath, sb *strings.Builder) error {
+	start := p.offset
+	p.offset++ // '\'
+	if p.offset >= len(p.data) {
+		return p.errorf(path, start, "unterminated string")
+	}
+
+	c := p.data[p.offset]
+	p.offset++
+
+	switch c {
+	case '"', '\\', '/':
+		sb.WriteByte(c)
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'r':
+		sb.WriteByte('\r')
+	case 't':
+		sb.WriteByte('\t')
+	case 'u':
+		r, err := p.parseHex(path, start, 4)
+		if err != nil {
+			return err
+		}
+		if utf16.IsSurrogate(r) {
+			// a lone surrogate is replaced with U+FFFD, as encoding/json does.
+			high := r
+			r = unicode.ReplacementChar
+			if p.hasLiteral(`\u`) {
+				lowStart := p.offset
+				p.offset += len(`\u`)
+				low, err := p.parseHex(path, lowStart, 4)
+				if decoded := utf16.DecodeRune(high, low); err == nil && decoded != unicode.ReplacementChar {
+					r = decoded
+				} else {
+					p.offset = lowStart
+				}
+			}
+			if r == unicode.ReplacementChar && p.disallowInvalidUTF8 {
+				return p.newError(codeInvalidUTF8Error, path, start, fmt.Errorf("%w: lone surrogate %q in string literal", ErrInvalidUTF8, p.data[start:start+len(`\uXXXX`)]))
+			}
+		}
+		sb.WriteRune(r)
+	case '\'':
+		if !p.relaxed {
+			return p.errorf(path, start, "invalid escape sequence %q in string literal", `\'`)
+		}
+		sb.WriteByte('\'')
+	case 'x':
+		if !p.relaxed {
+			return p.errorf(path, start, "invalid escape sequence %q in string literal", `\x`)
+		}
+		r, err := p.parseHex(path, start, 2)
+		if err != nil {
+			return err
+		}
+		sb.WriteRune(r)
+	case '\n':
+		if !p.relaxed {
+			return p.errorf(path, start, "invalid escape sequence in string literal")
+		}
+		// line continuation
+	case '\r':
+		if !p.relaxed {
+			return p.errorf(path, start, "invalid escape sequence in string literal")
+		}
+		// line continuation
+		if p.offset < len(p.data) && p.data[p.offset] == '\n' {
+			p.offset++
+		}
+	default:
+		return p.errorf(path, start, "invalid escape sequence %q in string literal", `\`+string(c))
+	}
+	return nil
+}
+
+func (p *parser) parseHex(path jsonpath, start int, digits int) (rune, error) {
+	if p.offset+digits > len(p.data) {
+		return 0, p.errorf(path, start, "invalid escape sequence in string literal")
+	}
+
+	v, err := strconv.ParseUint(string(p.data[p.offset:p.offset+digits]), 16, 32)
+	if err != nil {
+		return 0, p.errorf(path, start, "invalid escape sequence %q in string literal", string(p.data[start:p.offset+digits]))
+	}
+	p.offset += digits
+	return rune(v), nil
+}
+
+func (p *parser) parseNumber(path jsonpath) (jsonvalue, error) {
+	start := p.offset
+
+	neg := false
+	if c := p.data[p.offset]; c == '-' || c == '+' {
+		neg = c == '-'
+		p.offset++
+	}
+
+	if p.relaxed && (p.hasLiteral("0x") || p.hasLiteral("0X")) {
+		return p.parseHexNumber(path, start, neg)
+	}
+	if p.relaxed && (p.hasLiteral("Infinity") || p.hasLiteral("NaN")) {
+		return nil, p.errorf(path, start, "%s cannot be represented in JSON", string(p.data[start:p.offset])+p.identifierAt(p.offset))
+	}
+
+	intStart := p.offset
+	p.skipDigits()
+	intPart := string(p.data[intStart:p.offset])
+	if len(intPart) > 1 && intPart[0] == '0' {
+		p.offset = intStart + 1
+		return nil, p.errorf(path, p.offset, "invalid character %s in numeric literal", p.quoteChar())
+	}
+
+	fracPart := ""
+	hasDot := p.offset < len(p.data) && p.data[p.offset] == '.'
+	if hasDot {
+		p.offset++
+		fracStart := p.offset
+		p.skipDigits()
+		fracPart = string(p.data[fracStart:p.offset])
+	}
+
+	// JSON requires digits on both sides of the decimal point, JSON5 on either side.
+	if intPart == "" && (!p.relaxed || fracPart == "") ||
+		hasDot && fracPart == "" && !p.relaxed {
+		return nil, p.errorf(path, p.offset, "invalid character %s in numeric literal", p.quoteChar())
+	}
+
+	expPart := ""
+	if p.offset < len(p.data) && (p.data[p.offset] == 'e' || p.data[p.offset] == 'E') {
+		expStart := p.offset
+		p.offset++
+		if p.offset < len(p.data) && (p.data[p.offset] == '+' || p.data[p.offset] == '-') {
+			p.offset++
+		}
+		digitsStart := p.offset
+		p.skipDigits()
+		if p.offset == digitsStart {
+			return nil, p.errorf(path, p.offset, "invalid character %s in exponent of numeric literal", p.quoteChar())
+		}
+		expPart = string(p.data[expStart:p.offset])
+	}
+
+	// normalize to a JSON number literal
+	literal := intPart
+	if literal == "" {
+		literal = "0"
+	}
+	if fracPart != "" {
+		literal += "." + fracPart
+	}
+	literal += expPart
+	if neg {
+		literal = "-" + literal
+	}
+
+	return p.numberValue(path, start, literal)
+}
+
+func (p *parser) parseHexNumber(path jsonpath, start int, neg bool) (jsonvalue, error) {
+	p.offset += len("0x")
+	digitsStart := p.offset
+	for p.offset < len(p.data) && isHexDigit(p.data[p.offset]) {
+		p.offset++
+	}
+	if p.offset == digitsStart {
+		return nil, p.errorf(path, p.offset, "invalid character %s in hexadecimal literal", p.quoteChar())
+	}
+
+	i, _ := new(big.Int).SetString(string(p.data[digitsStart:p.offset]), 16)
+	if neg {
+		i.Neg(i)
+	}
+	return p.numberValue(path, start, i.String())
+}
+
+func (p *parser) numberValue(path jsonpath, start int, literal string) (jsonvalue, error) {
+	v, err := newNumber(literal, p.useNumber)
+	if err != nil {
+		return nil, p.errorf(path, start, "cannot unmarshal number %s into Go value of type float64", string(p.data[start:p.offset]))
+	}
+	return v, nil
+}
+
+func (p *parser) skipDigits() {
+	for p.offset < len(p.data) && '0' <= p.data[p.offset] && p.data[p.offset] <= '9' {
+		p.offset++
+	}
+}
+
+// skipSpace skips whitespace, and comments in relaxed mode.
+func (p *parser) skipSpace(path jsonpath) error {
+	for p.offset < len(p.data) {
+		switch p.data[p.offset] {
+		case ' ', '\t', '\r', '\n':
+			p.offset++
+
+		case '/':
+			if !p.relaxed || p.offset+1 >= len(p.data) {
+				return nil
+			}
+			switch p.data[p.offset+1] {
+			case '/':
+				end := bytes.IndexByte(p.data[p.offset:], '\n')
+				if end < 0 {
+					p.offset = len(p.data)
+				} else {
+					p.offset += end + 1
+				}
+			case '*':
+				end := bytes.Index(p.data[p.offset+2:], []byte("*/"))
+				if end < 0 {
+					return p.errorf(path, p.offset, "unterminated comment")
+				}
+				p.offset += 2 + end + 2
+			default:
+				return nil
+			}
+
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (p *parser) expect(path jsonpath, c byte, context string) error {
+	if p.offset >= len(p.data) {
+		return p.errorf(path, p.offset, "unexpected end of JSON input")
+	}
+	if p.data[p.offset] != c {
+		return p.errorf(path, p.offset, "invalid character %s %s", p.quoteChar(), context)
+	}
+	p.offset++
+	return nil
+}
+
+func (p *parser) hasLiteral(literal string) bool {
+	return bytes.HasPrefix(p.data[p.offset:], []byte(literal))
+}
+
+func (p *parser) runeAt(offset int) rune {
+	r, _ := utf8.DecodeRune(p.data[offset:])
+	return r
+}
+
+// identifierAt returns the identifier (as used for unquoted keys) starting at the given offset.
+func (p *parser) identifierAt(offset int) string {
+	end := offset
+	for end < len(p.data) {
+		r, size := utf8.DecodeRune(p.data[end:])
+		if !isIdentifierPart(r) {
+			break
+		}
+		end += size
+	}
+	return string(p.data[offset:end])
+}
+
+// quoteChar formats the character at the current offset for error messages, as encoding/json does.
+func (p *parser) quoteChar() string {
+	if p.offset >= len(p.data) {
+		return "EOF"
+	}
+
+	c := p.runeAt(p.offset)
+	if c == '\'' {
+		return `'\''`
+	}
+	if c == '"' {
+		return `'"'`
+	}
+	s := strconv.Quote(string(c))
+	return "'" + s[1:len(s)-1] + "'"
+}
+
+func (p *parser) errorf(path jsonpath, offset int, format string, args ...any) error {
+	return p.newError(codeSyntaxError, path, offset, fmt.Errorf(format, args...))
+}
+
+// newError creates a new NodeError for the given offset, with its position if positions are recorded,
+// and otherwise with the line and column in the message.
+func (p *parser) newError(code errCode, path jsonpath, offset int, err error) error {
+	if p.positions != nil {
+		return &NodeError{
+			code: code,
+			path: path,
+			pos:  p.positions.position(offset),
+			err:  err,
+		}
+	}
+
+	line, column := lineColumn(p.data, offset)
+	return &NodeError{
+		code: code,
+		path: path,
+		err:  fmt.Errorf("line %d, column %d: %w", line, column, err),
+	}
+}
+
+// lineColumn returns the 1-based line and column of the given offset in data.
+// The column counts characters, not bytes.
+func lineColumn(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+
+	line, lineStart := 1, 0
+	for i := 0; i < offset; i++ {
+		if data[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+	return line, utf8.RuneCount(data[lineStart:offset]) + 1
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || unicode.IsDigit(r)
+}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
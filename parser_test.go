@@ -0,0 +1,153 @@
+package jsond
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParserCompatibility(t *testing.T) {
+
+	tests := []string{
+		`null`,
+		`true`,
+		` false `,
+		`0`,
+		`-0.5e+10`,
+		`1E400`,
+		`"a\"\\\/\b\f\n\r\té😀"`,
+		`"\ud83d"`,
+		`"\ud83dA"`,
+		"\"\xff\"",
+		`{"a":[1,{"b":null}],"c":"d","a":2}`,
+		`[]`,
+		`{}`,
+		`[1,]`,
+		`{"a":1,}`,
+		`01`,
+		`1.`,
+		`.5`,
+		`+1`,
+		`1e`,
+		`"\x41"`,
+		`'a'`,
+		`{a:1}`,
+		"\"\t\"",
+		`[1 2]`,
+		`{"a" 1}`,
+		`[1]]`,
+		`tru`,
+		``,
+		`"abc`,
+	}
+
+	for _, src := range tests {
+		t.Run(src, func(t *testing.T) {
+			var want any
+			wantErr := json.Unmarshal([]byte(src), &want)
+
+			got, gotErr := newParser([]byte(src), parseConfig{}).parse()
+
+			if (gotErr != nil) != (wantErr != nil) {
+				t.Fatalf("\ngot  %v\nwant %v", gotErr, wantErr)
+			}
+			if wantErr == nil && !reflect.DeepEqual(got, want) {
+				t.Errorf("\ngot  %#v\nwant %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseRelaxed(t *testing.T) {
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "comments",
+			src: `// leading comment
+			{
+				/* block
+				   comment */
+				"a": 1, // trailing comment
+				"b": /* inline */ [true]
+			}`,
+			want: `{"a":1,"b":[true]}`,
+		},
+		{
+			name: "trailing commas",
+			src:  `{"a": [1, 2, ], "b": {"c": null, }, }`,
+			want: `{"a":[1,2],"b":{"c":null}}`,
+		},
+		{
+			name: "single-quoted strings",
+			src:  `{'a': 'it\'s "quoted"', "b": '\x41'}`,
+			want: `{"a":"it's \"quoted\"","b":"A"}`,
+		},
+		{
+			name: "unquoted keys",
+			src:  `{compilerOptions: {$schema: 1, _private: 2, target2: 3}}`,
+			want: `{"compilerOptions":{"$schema":1,"_private":2,"target2":3}}`,
+		},
+		{
+			name: "numbers",
+			src:  `[0xFF, -0x10, +1, .5, 5., 1e2]`,
+			want: `[255,-16,1,0.5,5,100]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseRelaxed([]byte(tt.src))
+			if got.err != nil {
+				t.Fatal(got.err)
+			}
+
+			want := Parse([]byte(tt.want))
+			if !reflect.DeepEqual(got.value, want.value) {
+				t.Errorf("\ngot  %#v\nwant %#v", got.value, want.value)
+			}
+		})
+	}
+}
+
+func TestParseRelaxedError(t *testing.T) {
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "invalid character",
+			src:  "{\n  \"a\": {\n    \"b\": x\n  }\n}",
+			want: "line 3, column 10: invalid character 'x' looking for beginning of value at $['a']['b']",
+		},
+		{
+			name: "unterminated comment",
+			src:  "[1, /* 2 ]",
+			want: "line 1, column 5: unterminated comment",
+		},
+		{
+			name: "missing comma",
+			src:  "{'a': 1\n 'b': 2}",
+			want: "line 2, column 2: invalid character '\\'' after object key:value pair",
+		},
+		{
+			name: "infinity",
+			src:  "[-Infinity]",
+			want: "line 1, column 2: -Infinity cannot be represented in JSON at $[0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ParseRelaxed([]byte(tt.src)).Error()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("\ngot  %v\nwant %s", err, tt.want)
+			}
+		})
+	}
+}
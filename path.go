@@ -1,6 +1,10 @@
 package jsond
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // jsonpath represents json jsonpath.
 type jsonpath []property
@@ -33,12 +37,34 @@ func (p jsonpath) String() string {
 	return joined
 }
 
+// key returns a string that identifies the path, for use as a map key.
+// Unlike String, it quotes object keys, so that different paths never have the same key.
+func (p jsonpath) key() string {
+	var b strings.Builder
+	for _, prop := range p {
+		switch t := prop.(type) {
+		case arrayIndex:
+			b.WriteString("[" + strconv.Itoa(int(t)) + "]")
+		case objectKey:
+			b.WriteString(strconv.Quote(string(t)))
+		default:
+			panic(fmt.Sprintf("invalid property. prop=%v, type=%t", prop, prop))
+		}
+	}
+	return b.String()
+}
+
+// append returns a new path with prop appended.
+// The returned path never shares its backing array with p, so sibling paths do not overwrite each other.
 func (p jsonpath) append(prop property) jsonpath {
+	newPath := make(jsonpath, len(p), len(p)+1)
+	copy(newPath, p)
+
 	switch t := prop.(type) {
 	case arrayIndex:
-		return append(p, t)
+		return append(newPath, t)
 	case objectKey:
-		return append(p, t)
+		return append(newPath, t)
 	default:
 		panic(fmt.Sprintf("invalid property. prop=%v, type=%t", prop, prop))
 	}
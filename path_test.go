@@ -0,0 +1,23 @@
+package jsond
+
+import "testing"
+
+func TestPathAppend(t *testing.T) {
+	// a path with spare capacity must not share its backing array with the paths appended to it
+	p := make(jsonpath, 0, 4)
+	p = p.append(objectKey("a"))
+
+	x := p.append(objectKey("x"))
+	y := p.append(objectKey("y"))
+	if x.String() != "$['a']['x']" || y.String() != "$['a']['y']" {
+		t.Errorf("\ngot  %s, %s\nwant $['a']['x'], $['a']['y']", x, y)
+	}
+
+	// sibling Nodes deep enough for their parent's path to have spare capacity
+	parent := Parse([]byte(`{"a":{"b":{"c":{"x":1,"y":2}}}}`)).Get("a", "b", "c")
+	first := parent.Get("x")
+	second := parent.Get("y")
+	if first.path.String() != "$['a']['b']['c']['x']" || second.path.String() != "$['a']['b']['c']['y']" {
+		t.Errorf("\ngot  %s, %s\nwant $['a']['b']['c']['x'], $['a']['b']['c']['y']", first.path, second.path)
+	}
+}
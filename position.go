@@ -0,0 +1,140 @@
+package jsond
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// Position describes a location in the parsed input.
+// The zero Position is not valid, and is returned for values whose position is unknown.
+type Position struct {
+	Filename string // filename, if any
+	Offset   int    // byte offset, starting at 0
+	Line     int    // line number, starting at 1
+	Column   int    // column number, starting at 1 (character count)
+}
+
+// IsValid reports whether the position is known.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position in the form "file:line:column", or "line:column" if there is no filename.
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	if p.Filename == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.Filename, p.Line, p.Column)
+}
+
+// WithPositions makes Parse record the position of each value and object key in the input,
+// available through Node.Position and Node.KeyPosition.
+// Syntax errors, and type errors returned by Unmarshal, then report the position as "file:line:column".
+// The filename is only used in positions and error messages, and may be empty.
+//
+// Positions are kept by the Nodes retrieved from the parsed Node with Get, AsArray and AsObject.
+// Nodes returned by Set and Filter describe new values, and have no positions.
+func WithPositions(filename string) ParseOption {
+	return func(c *parseConfig) {
+		c.positions = true
+		c.filename = filename
+	}
+}
+
+// Position returns the position of the Node's value in the parsed input.
+// It returns the zero Position if the Node was not parsed with WithPositions, or if the value does not exist.
+func (n *Node) Position() Position {
+	if n.positions == nil || n.err != nil {
+		return Position{}
+	}
+	s, ok := n.positions.lookup(n.path)
+	if !ok {
+		return Position{}
+	}
+	return n.positions.position(s.start)
+}
+
+// KeyPosition returns the position of the key of an object member in the parsed input.
+// It returns the zero Position if the Node is not an object member, or if its position is unknown.
+func (n *Node) KeyPosition() Position {
+	if n.positions == nil || n.err != nil {
+		return Position{}
+	}
+	s, ok := n.positions.lookup(n.path)
+	if !ok || s.key < 0 {
+		return Position{}
+	}
+	return n.positions.position(s.key)
+}
+
+// positionTable records where the values of a parsed input are located, keyed by the keys of their paths.
+type positionTable struct {
+	filename   string
+	data       []byte
+	lineStarts []int // offsets at which each line starts
+	spans      map[string]span
+}
+
+// span is the location of a value in the input.
+type span struct {
+	path  jsonpath
+	key   int // offset of the object key, or -1
+	start int // offset of the first byte of the value
+	end   int // offset just after the value
+}
+
+func newPositionTable(filename string, data []byte) *positionTable {
+	lineStarts := []int{0}
+	for i, c := range data {
+		if c == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	return &positionTable{
+		filename:   filename,
+		data:       data,
+		lineStarts: lineStarts,
+		spans:      map[string]span{},
+	}
+}
+
+func (t *positionTable) addValue(path jsonpath, start, end int) {
+	s, ok := t.lookup(path)
+	if !ok {
+		s.key = -1
+	}
+	s.path, s.start, s.end = path, start, end
+	t.spans[path.key()] = s
+}
+
+func (t *positionTable) addKey(path jsonpath, offset int) {
+	t.spans[path.key()] = span{key: offset}
+}
+
+// lookup returns the span of the value at path, if it is recorded.
+func (t *positionTable) lookup(path jsonpath) (span, bool) {
+	s, ok := t.spans[path.key()]
+	return s, ok
+}
+
+// position converts a byte offset into a Position.
+func (t *positionTable) position(offset int) Position {
+	if offset > len(t.data) {
+		offset = len(t.data)
+	}
+
+	line := sort.SearchInts(t.lineStarts, offset+1) // lineStarts[line-1] <= offset
+	lineStart := t.lineStarts[line-1]
+
+	return Position{
+		Filename: t.filename,
+		Offset:   offset,
+		Line:     line,
+		Column:   utf8.RuneCount(t.data[lineStart:offset]) + 1,
+	}
+}
@@ -0,0 +1,198 @@
+package jsond
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPosition(t *testing.T) {
+	src := []byte(`{
+	"name": "web",
+	"ports": [80, 443],
+	"env": {"DEBUG": true, "LANG": "é"}, "x": null
+}`)
+	root := Parse(src, WithPositions("config.json"))
+	if root.err != nil {
+		t.Fatal(root.err)
+	}
+
+	tests := []struct {
+		props   []any
+		want    string
+		wantKey string
+	}{
+		{props: []any{}, want: "config.json:1:1", wantKey: "-"},
+		{props: []any{"name"}, want: "config.json:2:10", wantKey: "config.json:2:2"},
+		{props: []any{"ports"}, want: "config.json:3:11", wantKey: "config.json:3:2"},
+		{props: []any{"ports", 1}, want: "config.json:3:16", wantKey: "-"},
+		{props: []any{"env", "LANG"}, want: "config.json:4:33", wantKey: "config.json:4:25"},
+		{props: []any{"x"}, want: "config.json:4:44", wantKey: "config.json:4:39"},
+		{props: []any{"missing"}, want: "-", wantKey: "-"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.props), func(t *testing.T) {
+			node := root.Get(tt.props...)
+			if got := node.Position().String(); got != tt.want {
+				t.Errorf("Position()\ngot  %s\nwant %s", got, tt.want)
+			}
+			if got := node.KeyPosition().String(); got != tt.wantKey {
+				t.Errorf("KeyPosition()\ngot  %s\nwant %s", got, tt.wantKey)
+			}
+		})
+	}
+
+	got := root.Get("env", "LANG").Position()
+	want := Position{Filename: "config.json", Offset: 71, Line: 4, Column: 33}
+	if got != want {
+		t.Errorf("\ngot  %#v\nwant %#v", got, want)
+	}
+
+	if pos := root.Set("api", "name").Get("name").Position(); pos.IsValid() {
+		t.Errorf("Set kept the position %s", pos)
+	}
+	if pos := Parse(src).Get("name").Position(); pos.IsValid() {
+		t.Errorf("Parse without WithPositions recorded the position %s", pos)
+	}
+}
+
+func TestPositionAmbiguousKey(t *testing.T) {
+	// the key "a']['b" and the path a → b have the same string form $['a']['b']
+	src := []byte(`{"a": {"b": 1}, "a']['b": "x"}`)
+	root := Parse(src, WithPositions(""))
+
+	tests := []struct {
+		node    *Node
+		want    string
+		wantKey string
+	}{
+		{node: root.Get("a", "b"), want: "1:13", wantKey: "1:8"},
+		{node: root.Get("a']['b"), want: "1:27", wantKey: "1:17"},
+	}
+	for _, tt := range tests {
+		if got := tt.node.Position().String(); got != tt.want {
+			t.Errorf("Position() at %s\ngot  %s\nwant %s", tt.node.path, got, tt.want)
+		}
+		if got := tt.node.KeyPosition().String(); got != tt.wantKey {
+			t.Errorf("KeyPosition() at %s\ngot  %s\nwant %s", tt.node.path, got, tt.wantKey)
+		}
+	}
+
+	var v struct {
+		A struct {
+			B string `json:"b"`
+		} `json:"a"`
+	}
+	want := "1:13: json: cannot unmarshal number into Go struct field .a.b of type string at $['a']['b']"
+	if err := root.Unmarshal(&v); err == nil || err.Error() != want {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+}
+
+func TestPositionError(t *testing.T) {
+	src := []byte(`{
+  "spec": {
+    "replicas": "3",
+    "ports": [80, {"port": 443}],
+    "labels": ["a", "b"]
+  }
+}`)
+
+	tests := []struct {
+		name string
+		f    func(*Node) error
+		want string
+	}{
+		{
+			name: "literal",
+			f: func(n *Node) error {
+				var v struct {
+					Spec struct {
+						Replicas int `json:"replicas"`
+					} `json:"spec"`
+				}
+				return n.Unmarshal(&v)
+			},
+			want: "deploy.json:3:17: json: cannot unmarshal string into Go struct field .spec.replicas of type int at $['spec']['replicas']",
+		},
+		{
+			name: "container",
+			f: func(n *Node) error {
+				var v []int
+				return n.Get("spec", "ports").Unmarshal(&v)
+			},
+			want: "deploy.json:4:19: json: cannot unmarshal object into .1 of type int at $['spec']['ports'][1]",
+		},
+		{
+			name: "child node",
+			f: func(n *Node) error {
+				var v int
+				return n.Get("spec", "replicas").Unmarshal(&v)
+			},
+			want: "deploy.json:3:17: json: cannot unmarshal string into Go value of type int at $['spec']['replicas']",
+		},
+		{
+			name: "no error",
+			f: func(n *Node) error {
+				var v []string
+				return n.Get("spec", "labels").Unmarshal(&v)
+			},
+			want: "",
+		},
+	}
+
+	root := Parse(src, WithPositions("deploy.json"))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.f(root)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPositionSyntaxError(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		parse    func([]byte, ...ParseOption) *Node
+		filename string
+		src      string
+		want     string
+	}{
+		{
+			name:     "strict",
+			parse:    Parse,
+			filename: "a.json",
+			src:      "{\n  \"a\": [1, 2,]\n}",
+			want:     "a.json:2:14: invalid character ']' looking for beginning of value at $['a'][2]",
+		},
+		{
+			name:     "relaxed",
+			parse:    ParseRelaxed,
+			filename: "a.json5",
+			src:      "{\n  // comment\n  a: 'x\n}",
+			want:     `a.json5:3:8: invalid character '\n' in string literal at $['a']`,
+		},
+		{
+			name:  "no filename",
+			parse: Parse,
+			src:   "[1,\n2 3]",
+			want:  "2:3: invalid character '3' after array element",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.parse([]byte(tt.src), WithPositions(tt.filename)).Error()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("\ngot  %v\nwant %s", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package jsond
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Resolver loads the documents referred to by JSON References.
+type Resolver interface {
+	// Resolve returns the document with the given URI, which has no fragment.
+	Resolve(uri string) (*Node, error)
+}
+
+// MemoryResolver is a Resolver that looks up documents in a map from URIs to Nodes.
+type MemoryResolver map[string]*Node
+
+// Resolve returns the document with the given URI.
+func (r MemoryResolver) Resolve(uri string) (*Node, error) {
+	doc, ok := r[uri]
+	if !ok {
+		return nil, fmt.Errorf("document %q not found", uri)
+	}
+	return doc, nil
+}
+
+// FSResolver is a Resolver that reads documents from a file system, using URIs as slash-separated paths.
+// Files with the extension .yaml or .yml are parsed with ParseYAML, and other files with Parse.
+// Parsed documents are cached, so each file is read only once.
+type FSResolver struct {
+	fsys fs.FS
+	opts []ParseOption
+
+	mu   sync.Mutex
+	docs map[string]*Node
+}
+
+// NewFSResolver returns a new FSResolver that reads from fsys.
+// The given options are applied when parsing JSON files.
+func NewFSResolver(fsys fs.FS, opts ...ParseOption) *FSResolver {
+	return &FSResolver{
+		fsys: fsys,
+		opts: opts,
+		docs: map[string]*Node{},
+	}
+}
+
+// Resolve reads and parses the file with the given path.
+func (r *FSResolver) Resolve(uri string) (*Node, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if doc, ok := r.docs[uri]; ok {
+		return doc, nil
+	}
+
+	data, err := fs.ReadFile(r.fsys, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc *Node
+	switch path.Ext(uri) {
+	case ".yaml", ".yml":
+		doc = ParseYAML(data)
+	default:
+		doc = Parse(data, r.opts...)
+	}
+	if doc.err != nil {
+		return nil, doc.err
+	}
+
+	r.docs[uri] = doc
+	return doc, nil
+}
+
+// ResolveRefs returns a new Node in which every JSON Reference, an object such as {"$ref": "other.json#/defs/x"},
+// is replaced by the value it refers to. References are resolved recursively, including the ones in the referred values.
+//
+// The part of a reference before '#' is a URI relative to the document containing the reference,
+// and the documents it refers to are loaded with resolver. The Node's own document has the empty URI.
+// The fragment is a JSON Pointer (RFC 6901) into that document; references with only a fragment refer to the same document.
+//
+// A reference that cannot be resolved, or that refers to itself directly or indirectly, makes ResolveRefs return a Node
+// with an error, at the path of the reference within the Node.
+func (n *Node) ResolveRefs(resolver Resolver) *Node {
+	if n.err != nil {
+		return n
+	}
+
+	root := n
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	r := &refResolver{
+		resolver: resolver,
+		docs:     map[string]*Node{"": root},
+	}
+	value, err := r.resolve(n.path, n.value, "", nil)
+	if err != nil {
+		return n.withError(err)
+	}
+
+	return &Node{
+		parent: n.parent,
+		value:  value,
+		path:   n.path,
+		err:    nil,
+	}
+}
+
+type refResolver struct {
+	resolver Resolver
+	docs     map[string]*Node // loaded documents by URI
+}
+
+// resolve returns a copy of v with its references resolved.
+// p is the path of v in the resulting Node, base is the URI of the document containing v,
+// and stack holds the references being resolved, to detect cycles.
+func (r *refResolver) resolve(p jsonpath, v jsonvalue, base string, stack []string) (jsonvalue, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		if ref, ok := t["$ref"].(string); ok {
+			return r.resolveRef(p, ref, base, stack)
+		}
+
+		// members are resolved in key order, so that the reported error does not depend on map iteration order
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		object := make(map[string]any, len(t))
+		for _, k := range keys {
+			resolved, err := r.resolve(p.append(objectKey(k)), t[k], base, stack)
+			if err != nil {
+				return nil, err
+			}
+			object[k] = resolved
+		}
+		return object, nil
+
+	case []any:
+		array := make([]any, len(t))
+		for i, elem := range t {
+			resolved, err := r.resolve(p.append(arrayIndex(i)), elem, base, stack)
+			if err != nil {
+				return nil, err
+			}
+			array[i] = resolved
+		}
+		return array, nil
+
+	default:
+		return v, nil
+	}
+}
+
+func (r *refResolver) resolveRef(p jsonpath, ref string, base string, stack []string) (jsonvalue, error) {
+	uri, pointer, _ := strings.Cut(ref, "#")
+	if uri == "" {
+		uri = base
+	} else {
+		uri = resolveURI(base, uri)
+	}
+
+	target := uri + "#" + pointer
+	for _, resolving := range stack {
+		if resolving == target {
+			return nil, newRefError(p, ref, fmt.Errorf("circular reference"))
+		}
+	}
+
+	doc, ok := r.docs[uri]
+	if !ok {
+		var err error
+		doc, err = r.resolver.Resolve(uri)
+		if err != nil {
+			return nil, newRefError(p, ref, err)
+		}
+		r.docs[uri] = doc
+	}
+
+	node, err := getPointer(doc, pointer)
+	if err != nil {
+		return nil, newRefError(p, ref, err)
+	}
+
+	return r.resolve(p, node.value, uri, append(stack[:len(stack):len(stack)], target))
+}
+
+// resolveURI resolves the URI ref relative to the URI base.
+func resolveURI(base, ref string) string {
+	if u, err := url.Parse(ref); err == nil && u.IsAbs() {
+		return ref
+	}
+	if b, err := url.Parse(base); err == nil && b.IsAbs() {
+		if u, err := b.Parse(ref); err == nil {
+			return u.String()
+		}
+	}
+	if strings.HasPrefix(ref, "/") {
+		return ref
+	}
+	return path.Join(path.Dir(base), ref)
+}
+
+// getPointer returns the Node that the JSON Pointer (RFC 6901), given as a URI fragment, refers to in doc.
+func getPointer(doc *Node, fragment string) (*Node, error) {
+	pointer, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON pointer %q", fragment)
+	}
+	if pointer == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	node := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		if _, ok := node.value.([]any); ok {
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || (len(token) > 1 && token[0] == '0') {
+				return nil, fmt.Errorf("invalid array index %q in JSON pointer %q", token, pointer)
+			}
+			node = node.Get(idx)
+		} else {
+			node = node.Get(token)
+		}
+		if node.err != nil {
+			return nil, fmt.Errorf("JSON pointer %q not found", pointer)
+		}
+	}
+	return node, nil
+}
@@ -0,0 +1,137 @@
+package jsond
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestResolveRefs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"api.json": {Data: []byte(`{
+			"paths": {
+				"/pets": {"get": {"responses": {"200": {"$ref": "common/responses.json#/ok"}}}},
+				"/users": {"get": {"responses": {"200": {"$ref": "#/components/userList"}}}}
+			},
+			"components": {
+				"user": {"type": "object"},
+				"userList": {"type": "array", "items": {"$ref": "#/components/user"}}
+			}
+		}`)},
+		"common/responses.json": {Data: []byte(`{
+			"ok": {"description": "OK", "content": {"$ref": "../schemas/pet.yaml#/pet"}}
+		}`)},
+		"schemas/pet.yaml": {Data: []byte("pet:\n  type: object\n  required: [name]\n")},
+	}
+
+	resolver := NewFSResolver(fsys)
+	api, err := resolver.Resolve("api.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := api.Get("paths").ResolveRefs(resolver)
+	if got.err != nil {
+		t.Fatal(got.err)
+	}
+
+	want := Parse([]byte(`{
+		"/pets": {"get": {"responses": {"200": {
+			"description": "OK",
+			"content": {"type": "object", "required": ["name"]}
+		}}}},
+		"/users": {"get": {"responses": {"200": {"type": "array", "items": {"type": "object"}}}}}
+	}`))
+	if !got.Equal(want) {
+		b, _ := got.Marshal()
+		t.Errorf("\ngot  %s", b)
+	}
+	if got.path.String() != "$['paths']" {
+		t.Errorf("\ngot  %s\nwant $['paths']", got.path)
+	}
+}
+
+func TestResolveRefsError(t *testing.T) {
+	resolver := MemoryResolver{
+		"other.json": Parse([]byte(`{"defs": {"a": {"$ref": "#/defs/b"}, "b": {"$ref": "#/defs/a"}, "list": [1, 2]}}`)),
+	}
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "cycle",
+			src:  `{"x": [{"$ref": "#/y"}], "y": {"next": {"$ref": "#/x"}}}`,
+			want: `cannot resolve $ref "#/y": circular reference at $['x'][0]['next'][0]`,
+		},
+		{
+			name: "cycle across documents",
+			src:  `{"x": {"$ref": "other.json#/defs/a"}}`,
+			want: `cannot resolve $ref "#/defs/a": circular reference at $['x']`,
+		},
+		{
+			name: "missing document",
+			src:  `{"x": {"$ref": "missing.json"}}`,
+			want: `cannot resolve $ref "missing.json": document "missing.json" not found at $['x']`,
+		},
+		{
+			name: "missing pointer",
+			src:  `{"x": {"$ref": "other.json#/defs/list/2"}}`,
+			want: `cannot resolve $ref "other.json#/defs/list/2": JSON pointer "/defs/list/2" not found at $['x']`,
+		},
+		{
+			name: "invalid pointer",
+			src:  `{"x": {"$ref": "#x"}}`,
+			want: `cannot resolve $ref "#x": invalid JSON pointer "x" at $['x']`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Parse([]byte(tt.src)).ResolveRefs(resolver).Error()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("\ngot  %v\nwant %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPointer(t *testing.T) {
+	// examples from RFC 6901 Section 5 and 6
+	doc := Parse([]byte(`{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"m~n": 8
+	}`))
+
+	tests := []struct {
+		pointer string
+		want    string
+	}{
+		{pointer: "", want: `{"":0,"a/b":1,"c%d":2,"e^f":3,"foo":["bar","baz"],"m~n":8}`},
+		{pointer: "/foo", want: `["bar","baz"]`},
+		{pointer: "/foo/0", want: `"bar"`},
+		{pointer: "/", want: `0`},
+		{pointer: "/a~1b", want: `1`},
+		{pointer: "/c%25d", want: `2`},
+		{pointer: "/e%5Ef", want: `3`},
+		{pointer: "/m~0n", want: `8`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			node, err := getPointer(doc, tt.pointer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := node.Marshal()
+			if string(got) != tt.want {
+				t.Errorf("\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
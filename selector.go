@@ -0,0 +1,169 @@
+package jsond
+
+import "sort"
+
+// Selector is a property that selects any number of child nodes.
+// When Get is called with a Selector, it returns a result set: a Node whose value is the array of the selected values,
+// and whose Nodes method returns the selected Nodes. Properties following the Selector are applied to each selected Node,
+// and the Nodes for which they do not exist are dropped from the result set.
+type Selector interface {
+	selectNodes(n *Node) []*Node
+}
+
+// All selects every element of an array, or every member of an object in key order.
+var All Selector = allSelector{}
+
+type allSelector struct{}
+
+func (allSelector) selectNodes(n *Node) []*Node {
+	return n.children()
+}
+
+// Where selects the elements of an array, or the members of an object in key order, for which f returns true.
+func Where(f func(*Node) bool) Selector {
+	return whereSelector{f: f}
+}
+
+type whereSelector struct {
+	f func(*Node) bool
+}
+
+func (s whereSelector) selectNodes(n *Node) []*Node {
+	selected := []*Node{}
+	for _, child := range n.children() {
+		if s.f(child) {
+			selected = append(selected, child)
+		}
+	}
+	return selected
+}
+
+// Recursive selects the values of the object members with the given key at any depth, in document order
+// (with object members in key order).
+func Recursive(key string) Selector {
+	return recursiveSelector{key: key}
+}
+
+type recursiveSelector struct {
+	key string
+}
+
+func (s recursiveSelector) selectNodes(n *Node) []*Node {
+	selected := []*Node{}
+	_, isObject := n.value.(map[string]any)
+	for _, child := range n.children() {
+		if isObject && child.path[len(child.path)-1] == objectKey(s.key) {
+			selected = append(selected, child)
+		}
+		selected = append(selected, s.selectNodes(child)...)
+	}
+	return selected
+}
+
+// children returns the elements of an array, or the members of an object in key order.
+// Other values, and Nodes with an error, have no children.
+func (n *Node) children() []*Node {
+	if n.err != nil {
+		return nil
+	}
+
+	switch t := n.value.(type) {
+	case []any:
+		nodes := make([]*Node, 0, len(t))
+		for i, v := range t {
+			nodes = append(nodes, n.newChild(v, arrayIndex(i), nil))
+		}
+		return nodes
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		nodes := make([]*Node, 0, len(t))
+		for _, k := range keys {
+			nodes = append(nodes, n.newChild(t[k], objectKey(k), nil))
+		}
+		return nodes
+	default:
+		return nil
+	}
+}
+
+// getSelected applies a property to a result set, or a Selector to any Node, and returns the resulting result set.
+func (n *Node) getSelected(prop any) *Node {
+	if n.err != nil {
+		return n
+	}
+
+	sources := n.results
+	if sources == nil {
+		sources = []*Node{n}
+	}
+
+	selected := []*Node{}
+	for _, source := range sources {
+		if sel, ok := prop.(Selector); ok {
+			selected = append(selected, sel.selectNodes(source)...)
+		} else if child := source.Get(prop); child.err == nil {
+			selected = append(selected, child)
+		}
+	}
+	return n.newResultSet(selected)
+}
+
+// newResultSet creates a result set Node of the given Nodes, with the path of n.
+func (n *Node) newResultSet(nodes []*Node) *Node {
+	values := make([]any, 0, len(nodes))
+	for _, node := range nodes {
+		values = append(values, node.value)
+	}
+
+	return &Node{
+		parent:  n.parent,
+		value:   values,
+		path:    n.path,
+		err:     nil,
+		results: nodes,
+	}
+}
+
+// IsResultSet reports whether the Node is a result set returned by Get with a Selector.
+func (n *Node) IsResultSet() bool {
+	return n.results != nil
+}
+
+// Nodes returns the Nodes selected by a result set.
+// For other Nodes, it returns the Node itself, or nil if the Node has an error.
+func (n *Node) Nodes() []*Node {
+	if n.results != nil {
+		return append([]*Node{}, n.results...)
+	}
+	if n.err != nil {
+		return nil
+	}
+	return []*Node{n}
+}
+
+// First returns the first Node selected by a result set, or an undefined Node if the result set is empty.
+// For other Nodes, it returns the Node itself.
+func (n *Node) First() *Node {
+	if n.results == nil {
+		return n
+	}
+	if len(n.results) == 0 {
+		return n.withError(newUndefined(n.path))
+	}
+	return n.results[0]
+}
+
+// selectorIndex returns the index of the first Selector in props, or -1 if there is none.
+func selectorIndex(props []any) int {
+	for i, prop := range props {
+		if _, ok := prop.(Selector); ok {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,136 @@
+package jsond
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelector(t *testing.T) {
+	root := Parse([]byte(`{
+		"store": {
+			"books": [
+				{"title": "A", "price": 8, "tags": ["x"]},
+				{"title": "B", "price": 12, "author": {"name": "Bob"}},
+				{"title": "C", "price": 23}
+			],
+			"bicycle": {"price": 19, "color": "red"}
+		}
+	}`))
+
+	expensive := Where(func(n *Node) bool {
+		var price float64
+		return n.Get("price").Unmarshal(&price) == nil && price > 10
+	})
+
+	tests := []struct {
+		name      string
+		props     []any
+		want      string
+		wantPaths []string
+	}{
+		{
+			name:      "all elements",
+			props:     []any{"store", "books", All, "title"},
+			want:      `["A","B","C"]`,
+			wantPaths: []string{"$['store']['books'][0]['title']", "$['store']['books'][1]['title']", "$['store']['books'][2]['title']"},
+		},
+		{
+			name:      "all members",
+			props:     []any{"store", "bicycle", All},
+			want:      `["red",19]`,
+			wantPaths: []string{"$['store']['bicycle']['color']", "$['store']['bicycle']['price']"},
+		},
+		{
+			name:      "missing members are dropped",
+			props:     []any{"store", "books", All, "author", "name"},
+			want:      `["Bob"]`,
+			wantPaths: []string{"$['store']['books'][1]['author']['name']"},
+		},
+		{
+			name:      "where",
+			props:     []any{"store", "books", expensive, "title"},
+			want:      `["B","C"]`,
+			wantPaths: []string{"$['store']['books'][1]['title']", "$['store']['books'][2]['title']"},
+		},
+		{
+			name:      "recursive",
+			props:     []any{Recursive("price")},
+			want:      `[19,8,12,23]`,
+			wantPaths: []string{"$['store']['bicycle']['price']", "$['store']['books'][0]['price']", "$['store']['books'][1]['price']", "$['store']['books'][2]['price']"},
+		},
+		{
+			name:      "nested selectors",
+			props:     []any{"store", All, All, "tags", -1},
+			want:      `["x"]`,
+			wantPaths: []string{"$['store']['books'][0]['tags'][0]"},
+		},
+		{
+			name:      "scalar",
+			props:     []any{"store", "bicycle", "color", All},
+			want:      `[]`,
+			wantPaths: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := root.Get(tt.props...)
+			if !got.IsResultSet() {
+				t.Fatalf("not a result set: %#v", got)
+			}
+
+			b, err := got.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("\ngot  %s\nwant %s", b, tt.want)
+			}
+
+			paths := []string{}
+			for _, n := range got.Nodes() {
+				paths = append(paths, n.path.String())
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("\ngot  %v\nwant %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestResultSet(t *testing.T) {
+	root := Parse([]byte(`{"items": [{"id": 1}, {"id": 2}]}`))
+	ids := root.Get("items", All, "id")
+
+	var got []int
+	if err := ids.Unmarshal(&got); err != nil || !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("\ngot  %v, %v\nwant [1 2]", got, err)
+	}
+
+	if first := ids.First(); first.value != float64(1) || first.path.String() != "$['items'][0]['id']" {
+		t.Errorf("\ngot  %v at %s\nwant 1 at $['items'][0]['id']", first.value, first.path)
+	}
+	if first := root.Get("items", All, "missing").First(); !first.IsUndefined() {
+		t.Errorf("\ngot  %#v\nwant undefined", first)
+	}
+
+	sum, err := Reduce(ids, 0, func(acc int, n *Node) (int, error) {
+		id, err := UnmarshalNode[int](n)
+		return acc + id, err
+	})
+	if err != nil || sum != 3 {
+		t.Errorf("\ngot  %v, %v\nwant 3", sum, err)
+	}
+
+	want := "cannot set values through a selector at $['items']"
+	if err := root.Set(0, "items", All, "id").Error(); err == nil || err.Error() != want {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+
+	if nodes := root.Get("items").Nodes(); len(nodes) != 1 || nodes[0].path.String() != "$['items']" {
+		t.Errorf("\ngot  %v\nwant the node itself", nodes)
+	}
+	if nodes := root.Get("missing").Nodes(); nodes != nil {
+		t.Errorf("\ngot  %v\nwant nil", nodes)
+	}
+}
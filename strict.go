@@ -0,0 +1,49 @@
+package jsond
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors wrapped by the NodeErrors that Parse returns for the violations rejected by the strict parse options,
+// and for data after the top-level value, which Parse always rejects.
+// Use errors.Is to check for them.
+var (
+	ErrDuplicateKey = errors.New("duplicate object key")
+	ErrInvalidUTF8  = errors.New("invalid UTF-8")
+	ErrTrailingData = errors.New("trailing data after top-level value")
+)
+
+// DisallowDuplicateKeys makes Parse reject objects that contain the same key more than once,
+// instead of keeping the last value. The error wraps ErrDuplicateKey and has the path of the repeated member.
+func DisallowDuplicateKeys() ParseOption {
+	return func(c *parseConfig) {
+		c.disallowDuplicateKeys = true
+	}
+}
+
+// DisallowInvalidUTF8 makes Parse reject strings that contain invalid UTF-8 or escaped lone surrogates (such as "\ud800"),
+// instead of replacing them with U+FFFD. The error wraps ErrInvalidUTF8.
+func DisallowInvalidUTF8() ParseOption {
+	return func(c *parseConfig) {
+		c.disallowInvalidUTF8 = true
+	}
+}
+
+// checkTrailingData returns an error wrapping ErrTrailingData if anything but whitespace follows
+// the top-level value that ends at offset in data.
+func checkTrailingData(path jsonpath, data []byte, offset int) error {
+	p := &parser{data: data, offset: offset}
+	if err := p.skipSpace(path); err != nil {
+		return err
+	}
+	if p.offset < len(p.data) {
+		return p.trailingDataError(path)
+	}
+	return nil
+}
+
+// trailingDataError creates a new NodeError for the data at the current offset, after the top-level value.
+func (p *parser) trailingDataError(path jsonpath) error {
+	return p.newError(codeTrailingDataError, path, p.offset, fmt.Errorf("%w: invalid character %s", ErrTrailingData, p.quoteChar()))
+}
@@ -0,0 +1,118 @@
+package jsond
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStrictParse(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		src      string
+		opts     []ParseOption
+		wantErr  error
+		wantCode errCode
+		want     string
+	}{
+		{
+			name:     "duplicate key",
+			src:      `{"a": {"b": 1, "c": 2, "b": 3}}`,
+			opts:     []ParseOption{DisallowDuplicateKeys()},
+			wantErr:  ErrDuplicateKey,
+			wantCode: codeDuplicateKeyError,
+			want:     `line 1, column 24: duplicate object key "b" at $['a']['b']`,
+		},
+		{
+			name:     "invalid utf-8",
+			src:      "[\"ok\", \"a\xffb\"]",
+			opts:     []ParseOption{DisallowInvalidUTF8()},
+			wantErr:  ErrInvalidUTF8,
+			wantCode: codeInvalidUTF8Error,
+			want:     `line 1, column 10: invalid UTF-8 in string literal at $[1]`,
+		},
+		{
+			name:     "lone high surrogate",
+			src:      `{"name": "\ud83d"}`,
+			opts:     []ParseOption{DisallowInvalidUTF8()},
+			wantErr:  ErrInvalidUTF8,
+			wantCode: codeInvalidUTF8Error,
+			want:     `line 1, column 11: invalid UTF-8: lone surrogate "\\ud83d" in string literal at $['name']`,
+		},
+		{
+			name:     "lone low surrogate",
+			src:      `{"name": "x\uDE00"}`,
+			opts:     []ParseOption{DisallowInvalidUTF8()},
+			wantErr:  ErrInvalidUTF8,
+			wantCode: codeInvalidUTF8Error,
+			want:     `line 1, column 12: invalid UTF-8: lone surrogate "\\uDE00" in string literal at $['name']`,
+		},
+		{
+			name:     "trailing data",
+			src:      `{"a": 1} {"a": 2}`,
+			wantErr:  ErrTrailingData,
+			wantCode: codeTrailingDataError,
+			want:     `line 1, column 10: trailing data after top-level value: invalid character '{'`,
+		},
+		{
+			name:     "trailing data with UseNumber",
+			src:      "[1]\n 2",
+			opts:     []ParseOption{UseNumber()},
+			wantErr:  ErrTrailingData,
+			wantCode: codeTrailingDataError,
+			want:     `line 2, column 2: trailing data after top-level value: invalid character '2'`,
+		},
+		{
+			name:     "trailing data with strict options",
+			src:      `{"a": 1}}`,
+			opts:     []ParseOption{DisallowDuplicateKeys(), DisallowInvalidUTF8()},
+			wantErr:  ErrTrailingData,
+			wantCode: codeTrailingDataError,
+			want:     `line 1, column 9: trailing data after top-level value: invalid character '}'`,
+		},
+		{
+			name:     "with positions",
+			src:      "{\n  \"id\": 1,\n  \"id\": 2\n}",
+			opts:     []ParseOption{DisallowDuplicateKeys(), WithPositions("a.json")},
+			wantErr:  ErrDuplicateKey,
+			wantCode: codeDuplicateKeyError,
+			want:     `a.json:3:3: duplicate object key "id" at $['id']`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Parse([]byte(tt.src), tt.opts...).Error()
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("\ngot  %v\nwant %s", err, tt.want)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%v is not %v", err, tt.wantErr)
+			}
+			var nodeErr *NodeError
+			if !errors.As(err, &nodeErr) || nodeErr.code != tt.wantCode {
+				t.Errorf("\ngot  %#v\nwant code %d", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestStrictParseAccepts(t *testing.T) {
+	src := `{"a": {"b": 1}, "b": {"b": 2}, "emoji": "😀", "pair": "\ud83d\ude00", "text": "héllo wörld"}  ` + "\n"
+	opts := []ParseOption{DisallowDuplicateKeys(), DisallowInvalidUTF8()}
+
+	got := Parse([]byte(src), opts...)
+	if got.err != nil {
+		t.Fatal(got.err)
+	}
+	if !got.Equal(Parse([]byte(src))) {
+		t.Errorf("\ngot  %#v\nwant %s", got.value, src)
+	}
+
+	// without the options, the same input is accepted as encoding/json does
+	for _, src := range []string{`{"a": 1, "a": 2}`, "\"a\xffb\"", `"\ud83d"`} {
+		if err := Parse([]byte(src)).Error(); err != nil {
+			t.Errorf("%s: %v", src, err)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package jsond
+
+import "errors"
+
+// elements returns the child nodes of an array Node, or the selected Nodes of a result set.
+// Unlike AsArray, a non-array value is reported as a NodeError with the Node's path.
+func (n *Node) elements() ([]*Node, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+	if n.results != nil {
+		return n.results, nil
+	}
+
+	array, ok := n.value.([]any)
+	if !ok {
+		return nil, newNotArrayError(n.path, n.value)
+	}
+
+	nodes := make([]*Node, 0, len(array))
+	for i, v := range array {
+		nodes = append(nodes, n.newChild(v, arrayIndex(i), nil))
+	}
+	return nodes, nil
+}
+
+// Map calls f for each element of the array node and returns the results.
+// If f returns an error, Map stops and returns the error annotated with the element's path.
+func Map[T any](node *Node, f func(*Node) (T, error)) ([]T, error) {
+	elems, err := node.elements()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]T, 0, len(elems))
+	for _, elem := range elems {
+		v, err := f(elem)
+		if err != nil {
+			return nil, node.locate(newCallbackError(elem.path, err))
+		}
+		results = append(results, v)
+	}
+	return results, nil
+}
+
+// Reduce calls f for each element of the array node, passing the result of the previous call
+// (or initial, for the first element), and returns the final result.
+// If f returns an error, Reduce stops and returns the error annotated with the element's path.
+func Reduce[T any](node *Node, initial T, f func(acc T, elem *Node) (T, error)) (T, error) {
+	elems, err := node.elements()
+	if err != nil {
+		return initial, err
+	}
+
+	acc := initial
+	for _, elem := range elems {
+		acc, err = f(acc, elem)
+		if err != nil {
+			return acc, node.locate(newCallbackError(elem.path, err))
+		}
+	}
+	return acc, nil
+}
+
+// Filter returns a new array Node containing the elements for which f returns true.
+// If the Node is not an array, it returns a new Node with the error.
+func (n *Node) Filter(f func(*Node) bool) *Node {
+	if n.err != nil {
+		return n
+	}
+
+	elems, err := n.elements()
+	if err != nil {
+		return n.withError(err)
+	}
+
+	filtered := []any{}
+	for _, elem := range elems {
+		if f(elem) {
+			filtered = append(filtered, elem.value)
+		}
+	}
+
+	return &Node{
+		parent: n.parent,
+		value:  filtered,
+		path:   n.path,
+		err:    nil,
+	}
+}
+
+// Slice returns a new array Node containing the elements from start up to, but not including, end,
+// taking every step-th element, with the same semantics as Python's array[start:end:step].
+// Negative start and end count from the end of the array, and out-of-range bounds are clamped to the array.
+// To slice to one of the ends of the array, pass math.MaxInt or math.MinInt as end.
+// If the Node is not an array or step is 0, it returns a new Node with the error.
+func (n *Node) Slice(start, end, step int) *Node {
+	if n.err != nil {
+		return n
+	}
+
+	array, ok := n.value.([]any)
+	if !ok {
+		return n.withError(newNotArrayError(n.path, n.value))
+	}
+	if step == 0 {
+		return n.withError(&NodeError{
+			code: codeInvalidIndexError,
+			path: n.path,
+			err:  errors.New("slice step cannot be zero"),
+		})
+	}
+
+	// bounds of the indexes, as computed by Python's slice.indices
+	lower, upper := 0, len(array)
+	if step < 0 {
+		lower, upper = -1, len(array)-1
+	}
+	clamp := func(i int) int {
+		if i < 0 {
+			i += len(array)
+		}
+		return min(max(i, lower), upper)
+	}
+	start, end = clamp(start), clamp(end)
+
+	sliced := []any{}
+	for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+		sliced = append(sliced, array[i])
+	}
+
+	return &Node{
+		parent: n.parent,
+		value:  sliced,
+		path:   n.path,
+		err:    nil,
+	}
+}
+
+// withError returns a copy of the Node with the given error, located in the parsed input.
+func (n *Node) withError(err error) *Node {
+	return &Node{
+		parent:    n.parent,
+		value:     n.value,
+		path:      n.path,
+		err:       n.locate(err),
+		positions: n.positions,
+	}
+}
+
+// locate returns err with the position of its path in the parsed input, if err is a NodeError without a position
+// and the Node was parsed with WithPositions. The NodeError is copied, since it may be shared.
+func (n *Node) locate(err error) error {
+	nodeErr, ok := err.(*NodeError)
+	if !ok || n.positions == nil || nodeErr.pos.IsValid() {
+		return err
+	}
+	s, ok := n.positions.lookup(nodeErr.path)
+	if !ok {
+		return err
+	}
+
+	located := *nodeErr
+	located.pos = n.positions.position(s.start)
+	return &located
+}
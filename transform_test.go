@@ -0,0 +1,143 @@
+package jsond
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestMapError(t *testing.T) {
+	root := Parse([]byte(`{"items":[{"id":1},{"id":2},{"id":"3"}]}`))
+
+	tests := []struct {
+		name string
+		f    func(*Node) (int, error)
+		want string
+	}{
+		{
+			name: "node error",
+			f:    func(n *Node) (int, error) { return UnmarshalNode[int](n.Get("id")) },
+			want: "json: cannot unmarshal string into Go value of type int at $['items'][2]['id']",
+		},
+		{
+			name: "callback error",
+			f: func(n *Node) (int, error) {
+				if n.Get("id").Equal(Parse([]byte(`2`))) {
+					return 0, errors.New("unexpected id")
+				}
+				return 0, nil
+			},
+			want: "unexpected id at $['items'][1]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Map(root.Get("items"), tt.f)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("\ngot  %v\nwant %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterNotArray(t *testing.T) {
+	got := Parse([]byte(`{"items":{}}`)).
+		Get("items").
+		Filter(func(*Node) bool { return true })
+
+	want := "object is not an array at $['items']"
+	if err := got.Error(); err == nil || err.Error() != want {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+}
+
+func TestSlice(t *testing.T) {
+	root := Parse([]byte(`{"items":[0,1,2,3,4,5]}`))
+
+	tests := []struct {
+		start, end, step int
+		want             string
+	}{
+		{start: 1, end: 4, step: 1, want: "[1,2,3]"},
+		{start: 0, end: math.MaxInt, step: 2, want: "[0,2,4]"},
+		{start: -2, end: math.MaxInt, step: 1, want: "[4,5]"},
+		{start: 0, end: -1, step: 1, want: "[0,1,2,3,4]"},
+		{start: -100, end: 100, step: 1, want: "[0,1,2,3,4,5]"},
+		{start: 4, end: 1, step: 1, want: "[]"},
+		{start: -1, end: math.MinInt, step: -1, want: "[5,4,3,2,1,0]"},
+		{start: 5, end: 0, step: -2, want: "[5,3,1]"},
+		{start: 100, end: -100, step: -3, want: "[5,2]"},
+		{start: 1, end: 2, step: 0, want: "slice step cannot be zero at $['items']"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("[%d:%d:%d]", tt.start, tt.end, tt.step), func(t *testing.T) {
+			got, err := root.Get("items").Slice(tt.start, tt.end, tt.step).Marshal()
+			if err != nil {
+				got = []byte(err.Error())
+			}
+			if string(got) != tt.want {
+				t.Errorf("\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+
+	if err := root.Slice(0, 1, 1).Error(); err == nil || err.Error() != "object is not an array" {
+		t.Errorf("\ngot  %v\nwant object is not an array", err)
+	}
+}
+
+func TestTransformErrorPosition(t *testing.T) {
+	root := Parse([]byte("{\n  \"items\": [1, \"2\"],\n  \"name\": \"x\"\n}"), WithPositions("a.json"))
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "filter",
+			err:  root.Get("name").Filter(func(*Node) bool { return true }).Error(),
+			want: "a.json:3:11: string is not an array at $['name']",
+		},
+		{
+			name: "slice",
+			err:  root.Get("items").Slice(0, 1, 0).Error(),
+			want: "a.json:2:12: slice step cannot be zero at $['items']",
+		},
+		{
+			name: "map",
+			err: func() error {
+				_, err := Map(root.Get("items"), func(n *Node) (int, error) {
+					if !n.IsNumber() {
+						return 0, errors.New("not a number")
+					}
+					return 0, nil
+				})
+				return err
+			}(),
+			want: "a.json:2:16: not a number at $['items'][1]",
+		},
+		{
+			name: "reduce",
+			err: func() error {
+				_, err := Reduce(root.Get("items"), 0, func(acc int, n *Node) (int, error) {
+					v, err := UnmarshalNode[int](n)
+					return acc + v, err
+				})
+				return err
+			}(),
+			want: "a.json:2:16: json: cannot unmarshal string into Go value of type int at $['items'][1]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil || tt.err.Error() != tt.want {
+				t.Errorf("\ngot  %v\nwant %s", tt.err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package jsond
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Validator checks a Node against a set of rules, each applied to the value at a path.
+// Unlike a chain of Get calls, which stops at the first missing value, Validate runs every rule
+// and reports all the failures at once.
+//
+// A path may contain Selectors, in which case the rest of the path is checked for each selected Node.
+// For example, Required("comments", All, "author") requires every comment to have an author.
+type Validator struct {
+	rules []validationRule
+}
+
+type validationRule struct {
+	props []any
+	check func(n *Node) error // called for the Node at the path, whether or not it exists
+}
+
+// NewValidator creates a Validator without rules.
+func NewValidator() *Validator {
+	return &Validator{}
+}
+
+// Required adds a rule that the value at the path exists. A null value exists.
+func (v *Validator) Required(props ...any) *Validator {
+	return v.add(props, func(n *Node) error {
+		if n.err != nil {
+			return errors.New("required value is missing")
+		}
+		return nil
+	})
+}
+
+// Type adds a rule that the value at the path, if it exists, is of the given kind.
+// It panics if kind is not the kind of a JSON value.
+func (v *Validator) Type(kind Kind, props ...any) *Validator {
+	if kind < KindNull || kind > KindObject {
+		panic(fmt.Sprintf("invalid kind. kind=%v", kind))
+	}
+
+	return v.add(props, func(n *Node) error {
+		if n.err != nil {
+			return nil
+		}
+		if got := kindOf(n.value); got != kind {
+			return fmt.Errorf("expected %s, but got %s", kind, got)
+		}
+		return nil
+	})
+}
+
+// Range adds a rule that the value at the path, if it exists, is a number between min and max inclusive.
+func (v *Validator) Range(min, max float64, props ...any) *Validator {
+	lower := new(big.Rat).SetFloat64(min)
+	upper := new(big.Rat).SetFloat64(max)
+	if lower == nil || upper == nil {
+		panic(fmt.Sprintf("invalid range. min=%v, max=%v", min, max))
+	}
+
+	return v.add(props, func(n *Node) error {
+		if n.err != nil {
+			return nil
+		}
+		if !isNumber(n.value) {
+			return fmt.Errorf("expected %s, but got %s", KindNumber, kindOf(n.value))
+		}
+		r, ok := numberRat(n.value)
+		if !ok || r.Cmp(lower) < 0 || r.Cmp(upper) > 0 {
+			return fmt.Errorf("%s is out of range [%v, %v]", numberLiteral(n.value), min, max)
+		}
+		return nil
+	})
+}
+
+func (v *Validator) add(props []any, check func(n *Node) error) *Validator {
+	for _, prop := range props {
+		if _, ok := prop.(Selector); ok {
+			continue
+		}
+		if _, err := getProperty(prop); err != nil {
+			panic(fmt.Sprintf("invalid property. prop=%v, type=%T", prop, prop))
+		}
+	}
+
+	v.rules = append(v.rules, validationRule{props: props, check: check})
+	return v
+}
+
+// Validate runs all the rules against n, in the order they were added.
+// It returns nil if every rule passes, and otherwise an error joining a NodeError for each failure,
+// which can be listed with errors.Join's Unwrap() []error method.
+// If n has an error, Validate returns the error without running the rules.
+func (v *Validator) Validate(n *Node) error {
+	if n.err != nil {
+		return n.err
+	}
+
+	var errs []error
+	for _, rule := range v.rules {
+		for _, target := range validationTargets(n, rule.props) {
+			if err := rule.check(target); err != nil {
+				errs = append(errs, newValidationError(target.path, target.Position(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Require checks that the values at all the paths exist, and reports every missing one.
+// Each path is a list of properties as passed to Get.
+//
+//	err := n.Require([]any{"name"}, []any{"address", "city"})
+func (n *Node) Require(paths ...[]any) error {
+	v := NewValidator()
+	for _, path := range paths {
+		v.Required(path...)
+	}
+	return v.Validate(n)
+}
+
+// validationTargets returns the Nodes at the path. The part of the path after a Selector is followed from each selected Node,
+// so that a missing value is reported for each of them, instead of being dropped from the result set.
+func validationTargets(n *Node, props []any) []*Node {
+	i := selectorIndex(props)
+	if i < 0 {
+		return []*Node{n.Get(props...)}
+	}
+
+	var targets []*Node
+	for _, selected := range n.Get(props[:i+1]...).Nodes() {
+		targets = append(targets, validationTargets(selected, props[i+1:])...)
+	}
+	return targets
+}
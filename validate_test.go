@@ -0,0 +1,139 @@
+package jsond
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	src := []byte(`{
+		"name": "jsond",
+		"version": null,
+		"port": 70000,
+		"ratio": "0.5",
+		"tags": ["a", 1],
+		"authors": [{"name": "Alice"}, {"email": "bob@example.com"}]
+	}`)
+
+	tests := []struct {
+		name      string
+		validator *Validator
+		want      []string
+	}{
+		{
+			name: "all pass",
+			validator: NewValidator().
+				Required("name").
+				Required("version").
+				Type(KindString, "name").
+				Type(KindNull, "version").
+				Range(1, 65535, "missing").
+				Required("authors", 0, "name"),
+			want: nil,
+		},
+		{
+			name: "required",
+			validator: NewValidator().
+				Required("description").
+				Required("owner", "name").
+				Required("tags", 5).
+				Required("version", "major"),
+			want: []string{
+				"required value is missing at $['description']",
+				"required value is missing at $['owner']['name']",
+				"required value is missing at $['tags'][5]",
+				"required value is missing at $['version']['major']",
+			},
+		},
+		{
+			name: "type",
+			validator: NewValidator().
+				Type(KindNumber, "name").
+				Type(KindArray, "authors").
+				Type(KindObject, "tags").
+				Type(KindBool, "version"),
+			want: []string{
+				"expected number, but got string at $['name']",
+				"expected object, but got array at $['tags']",
+				"expected bool, but got null at $['version']",
+			},
+		},
+		{
+			name: "range",
+			validator: NewValidator().
+				Range(1, 65535, "port").
+				Range(0, 1, "ratio").
+				Range(0, 1e6, "port"),
+			want: []string{
+				"70000 is out of range [1, 65535] at $['port']",
+				"expected number, but got string at $['ratio']",
+			},
+		},
+		{
+			name: "selector",
+			validator: NewValidator().
+				Required("authors", All, "name").
+				Type(KindString, "tags", All),
+			want: []string{
+				"required value is missing at $['authors'][1]['name']",
+				"expected string, but got number at $['tags'][1]",
+			},
+		},
+	}
+
+	root := Parse(src)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validator.Validate(root)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("\ngot  %v\nwant nil", err)
+				}
+				return
+			}
+
+			joined, ok := err.(interface{ Unwrap() []error })
+			if !ok {
+				t.Fatalf("\ngot  %#v\nwant joined errors", err)
+			}
+			errs := joined.Unwrap()
+			if len(errs) != len(tt.want) {
+				t.Fatalf("\ngot  %v\nwant %v", errs, tt.want)
+			}
+			for i, err := range errs {
+				var nodeErr *NodeError
+				if !errors.As(err, &nodeErr) || err.Error() != tt.want[i] {
+					t.Errorf("\ngot  %v\nwant %s", err, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidatorPosition(t *testing.T) {
+	root := Parse([]byte("{\n  \"port\": \"80\"\n}"), WithPositions("config.json"))
+	err := NewValidator().Type(KindNumber, "port").Validate(root)
+	want := "config.json:2:11: expected number, but got string at $['port']"
+	if err == nil || err.Error() != want {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	root := Parse([]byte(`{"user": {"name": "Alice"}}`))
+
+	if err := root.Require([]any{"user"}, []any{"user", "name"}); err != nil {
+		t.Errorf("\ngot  %v\nwant nil", err)
+	}
+
+	err := root.Require([]any{"id"}, []any{"user", "name"}, []any{"user", "email"})
+	want := "required value is missing at $['id']\nrequired value is missing at $['user']['email']"
+	if err == nil || err.Error() != want {
+		t.Errorf("\ngot  %v\nwant %s", err, want)
+	}
+
+	invalid := Parse([]byte(`{`))
+	if err := invalid.Require([]any{"id"}); err != invalid.Error() {
+		t.Errorf("\ngot  %v\nwant %v", err, invalid.Error())
+	}
+}
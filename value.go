@@ -3,6 +3,9 @@ package jsond
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
 )
 
 // jsonvalue represents a json.Unmarshal result.
@@ -10,6 +13,8 @@ import (
 // It stores one of these in the any value:
 // - bool, for JSON booleans
 // - float64, for JSON numbers
+// - json.Number, for JSON numbers parsed with UseNumber
+// - int64 and uint64, for integers decoded from binary formats or set from Go values
 // - string, for JSON strings
 // - []any, for JSON arrays
 // - map[string]any, for JSON objects
@@ -24,17 +29,30 @@ func getJSONValue(v any) (jsonvalue, error) {
 	switch t := v.(type) {
 	case *Node:
 		return t.value, t.err
+	case Node:
+		return t.value, t.err
 	case bool, float64, string:
 		return v, nil
-	default:
-		data, err := json.Marshal(v)
-		if err != nil {
-			return nil, err
+	case json.Number:
+		if !isValidNumber(t) {
+			return nil, fmt.Errorf("invalid number literal %q", t)
 		}
+		return v, nil
+	default:
+		return encodeValue(v)
+	}
+}
 
-		jv := *new(jsonvalue)
-		err = json.Unmarshal(data, &jv)
-		return jv, err
+// getOwnedJSONValue is like getJSONValue, but the result shares no containers with v,
+// including the values of Nodes within v, so that it can be modified in place.
+func getOwnedJSONValue(v any) (jsonvalue, error) {
+	switch v.(type) {
+	case nil, *Node, Node, bool, float64, string, json.Number:
+		jv, err := getJSONValue(v)
+		return copyValue(jv), err
+	default:
+		e := &encoder{copyNodes: true}
+		return e.value(reflect.ValueOf(v))
 	}
 }
 
@@ -42,7 +60,7 @@ func getTypeString(v jsonvalue) string {
 	switch v.(type) {
 	case bool:
 		return "bool"
-	case float64:
+	case float64, json.Number, int64, uint64:
 		return "number"
 	case string:
 		return "string"
@@ -56,3 +74,70 @@ func getTypeString(v jsonvalue) string {
 		panic(fmt.Sprintf("invalid jsonvalue. v=%v", v))
 	}
 }
+
+// isNumber reports whether v holds a JSON number.
+func isNumber(v jsonvalue) bool {
+	switch v.(type) {
+	case float64, json.Number, int64, uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// numberFloat64 returns the JSON number v as a float64.
+func numberFloat64(v jsonvalue) (float64, error) {
+	switch t := v.(type) {
+	case float64:
+		return t, nil
+	case json.Number:
+		return strconv.ParseFloat(string(t), 64)
+	case int64:
+		return float64(t), nil
+	case uint64:
+		return float64(t), nil
+	default:
+		return 0, fmt.Errorf("invalid number. v=%v", v)
+	}
+}
+
+// numberRat returns the JSON number v as an exact rational number.
+// It returns false if v is not a finite number.
+func numberRat(v jsonvalue) (*big.Rat, bool) {
+	switch t := v.(type) {
+	case float64:
+		r := new(big.Rat)
+		if r.SetFloat64(t) == nil {
+			return nil, false
+		}
+		return r, true
+	case json.Number:
+		return new(big.Rat).SetString(string(t))
+	case int64:
+		return new(big.Rat).SetInt64(t), true
+	case uint64:
+		return new(big.Rat).SetUint64(t), true
+	default:
+		return nil, false
+	}
+}
+
+// isValidNumber reports whether n is a valid JSON number literal.
+func isValidNumber(n json.Number) bool {
+	if n == "" {
+		return false
+	}
+	if c := n[0]; c != '-' && (c < '0' || c > '9') {
+		return false
+	}
+	return json.Valid([]byte(n))
+}
+
+// newNumber returns the JSON number literal as a jsonvalue,
+// either as a json.Number or as a float64 as encoding/json does.
+func newNumber(literal string, useNumber bool) (jsonvalue, error) {
+	if useNumber {
+		return json.Number(literal), nil
+	}
+	return strconv.ParseFloat(literal, 64)
+}
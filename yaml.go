@@ -0,0 +1,1367 @@
+package jsond
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseYAML parses a YAML document and returns a Node representing its structure.
+// It supports the JSON-compatible subset of YAML 1.2: block and flow collections,
+// plain, quoted and block scalars typed by the core schema, anchors and aliases
+// (which are expanded), and merge keys.
+// Only UseNumber is honored among the given options.
+// If the data contains more than one document, use ParseYAMLStream instead.
+func ParseYAML(data []byte, opts ...ParseOption) *Node {
+	docs, err := parseYAML(data, opts)
+	if err != nil {
+		return &Node{
+			parent: nil,
+			value:  nil,
+			path:   jsonpath{},
+			err:    err,
+		}
+	}
+
+	if len(docs) > 1 {
+		return &Node{
+			parent: nil,
+			value:  nil,
+			path:   jsonpath{},
+			err:    newYAMLError(jsonpath{}, fmt.Errorf("expected a single document, but found %d", len(docs))),
+		}
+	}
+
+	var value jsonvalue
+	if len(docs) == 1 {
+		value = docs[0]
+	}
+	return &Node{
+		parent: nil,
+		value:  value,
+		path:   jsonpath{},
+		err:    nil,
+	}
+}
+
+// ParseYAMLStream parses a stream of YAML documents separated by "---" and returns a Node for each document.
+func ParseYAMLStream(data []byte, opts ...ParseOption) ([]*Node, error) {
+	docs, err := parseYAML(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*Node, 0, len(docs))
+	for _, doc := range docs {
+		nodes = append(nodes, &Node{
+			parent: nil,
+			value:  doc,
+			path:   jsonpath{},
+			err:    nil,
+		})
+	}
+	return nodes, nil
+}
+
+func parseYAML(data []byte, opts []ParseOption) ([]jsonvalue, error) {
+	cfg := parseConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	p := &yamlParser{
+		data:      bytes.TrimPrefix(data, []byte("\ufeff")),
+		anchors:   map[string]jsonvalue{},
+		useNumber: cfg.useNumber,
+	}
+	return p.parseStream()
+}
+
+// yamlParser is a recursive descent parser for the JSON-compatible subset of YAML 1.2.
+type yamlParser struct {
+	data      []byte
+	offset    int
+	lineStart int // offset of the first byte of the current line
+	depth     int
+	anchors   map[string]jsonvalue
+	useNumber bool
+}
+
+func (p *yamlParser) parseStream() ([]jsonvalue, error) {
+	docs := []jsonvalue{}
+	root := jsonpath{}
+
+	for {
+		if err := p.skipSpace(root); err != nil {
+			return nil, err
+		}
+		for p.column() == 0 && p.peek() == '%' {
+			// directives such as %YAML are ignored
+			p.skipLine()
+			if err := p.skipSpace(root); err != nil {
+				return nil, err
+			}
+		}
+		if p.eof() {
+			return docs, nil
+		}
+
+		if p.hasMarker("...") {
+			p.skipLine()
+			continue
+		}
+		if p.hasMarker("---") {
+			p.offset += len("---")
+		}
+
+		v, err := p.parseBlockNode(-1, root, false, false)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, v)
+
+		if err := p.skipSpace(root); err != nil {
+			return nil, err
+		}
+		if !p.eof() && !p.hasMarker("---") && !p.hasMarker("...") {
+			return nil, p.errorf(root, p.offset, "unexpected content after document")
+		}
+	}
+}
+
+// parseBlockNode parses a node in block context.
+// The node must be indented more than parentIndent, unless it is a block sequence and sameIndentSeq is set
+// (a sequence may have the same indentation as the mapping key it belongs to).
+// compact reports whether a block collection may start on the current line, as it may after "- ".
+func (p *yamlParser) parseBlockNode(parentIndent int, path jsonpath, compact bool, sameIndentSeq bool) (jsonvalue, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxParseDepth {
+		return nil, p.errorf(path, p.offset, "exceeded max depth")
+	}
+
+	if err := p.skipSpace(path); err != nil {
+		return nil, err
+	}
+	if p.isEmptyNode(parentIndent, sameIndentSeq) {
+		return nil, nil
+	}
+
+	anchor, tag, err := p.parseProperties(path)
+	if err != nil {
+		return nil, err
+	}
+	if anchor != "" || tag != "" {
+		if err := p.skipSpace(path); err != nil {
+			return nil, err
+		}
+		if p.isEmptyNode(parentIndent, sameIndentSeq) {
+			v, err := p.resolveTagged(path, p.offset, tag, "", true)
+			if err != nil {
+				return nil, err
+			}
+			p.setAnchor(anchor, v)
+			return v, nil
+		}
+	}
+
+	v, err := p.parseBlockContent(parentIndent, path, compact || p.atLineContentStart(), tag)
+	if err != nil {
+		return nil, err
+	}
+	p.setAnchor(anchor, v)
+	return v, nil
+}
+
+// isEmptyNode reports whether there is no node content at the current position.
+func (p *yamlParser) isEmptyNode(parentIndent int, sameIndentSeq bool) bool {
+	if p.eof() || p.hasMarker("---") || p.hasMarker("...") {
+		return true
+	}
+	if !p.atLineContentStart() {
+		return false
+	}
+	if p.column() > parentIndent {
+		return false
+	}
+	return !(sameIndentSeq && p.column() == parentIndent && p.atSequenceEntry())
+}
+
+func (p *yamlParser) parseBlockContent(parentIndent int, path jsonpath, compact bool, tag string) (jsonvalue, error) {
+	start := p.offset
+	column := p.column()
+
+	switch c := p.peek(); {
+	case c == '-' && p.atSequenceEntry():
+		if !compact {
+			return nil, p.errorf(path, start, "block sequence entries are not allowed in this context")
+		}
+		return p.parseBlockSequence(column, path)
+
+	case c == '|' || c == '>':
+		s, err := p.parseBlockScalar(parentIndent, path)
+		if err != nil {
+			return nil, err
+		}
+		return p.resolveTagged(path, start, tag, s, false)
+
+	case c == '[' || c == '{':
+		return p.parseFlowNode(path)
+
+	case c == '*':
+		return p.parseAlias(path)
+
+	case c == '?':
+		if p.atIndicator(1) {
+			return nil, p.errorf(path, start, "complex mapping keys are not supported")
+		}
+	}
+
+	if p.isMappingKey() {
+		if !compact {
+			return nil, p.errorf(path, start, "mapping values are not allowed in this context")
+		}
+		return p.parseBlockMapping(column, path)
+	}
+
+	s, plain, err := p.parseScalar(parentIndent, path, false)
+	if err != nil {
+		return nil, err
+	}
+	return p.resolveTagged(path, start, tag, s, plain)
+}
+
+func (p *yamlParser) parseBlockSequence(indent int, path jsonpath) (jsonvalue, error) {
+	array := []any{}
+
+	for {
+		p.offset++ // '-'
+
+		v, err := p.parseBlockNode(indent, path.append(arrayIndex(len(array))), true, false)
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, v)
+
+		if err := p.skipSpace(path); err != nil {
+			return nil, err
+		}
+		if p.eof() || p.hasMarker("---") || p.hasMarker("...") {
+			return array, nil
+		}
+		if !p.atLineContentStart() {
+			return nil, p.errorf(path, p.offset, "unexpected content after sequence entry")
+		}
+		if p.column() < indent || (p.column() == indent && !p.atSequenceEntry()) {
+			return array, nil
+		}
+		if p.column() > indent {
+			return nil, p.errorf(path, p.offset, "bad indentation of a sequence entry")
+		}
+	}
+}
+
+func (p *yamlParser) parseBlockMapping(indent int, path jsonpath) (jsonvalue, error) {
+	object := map[string]any{}
+	merges := []map[string]any{}
+
+	for {
+		keyStart := p.offset
+		key, isMerge, err := p.parseMappingKey(path)
+		if err != nil {
+			return nil, err
+		}
+		elemPath := path.append(objectKey(key))
+
+		p.skipInlineSpace()
+		if p.peek() != ':' {
+			return nil, p.errorf(elemPath, p.offset, "could not find expected ':'")
+		}
+		p.offset++
+
+		valueStart := p.offset
+		v, err := p.parseBlockNode(indent, elemPath, false, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if isMerge {
+			merged, err := p.mergeSources(elemPath, valueStart, v)
+			if err != nil {
+				return nil, err
+			}
+			merges = append(merges, merged...)
+		} else {
+			if _, ok := object[key]; ok {
+				return nil, p.errorf(elemPath, keyStart, "mapping key %q already defined", key)
+			}
+			object[key] = v
+		}
+
+		if err := p.skipSpace(path); err != nil {
+			return nil, err
+		}
+		if p.eof() || p.hasMarker("---") || p.hasMarker("...") {
+			break
+		}
+		if !p.atLineContentStart() {
+			return nil, p.errorf(elemPath, p.offset, "mapping values are not allowed in this context")
+		}
+		if p.column() < indent {
+			break
+		}
+		if p.column() > indent {
+			return nil, p.errorf(path, p.offset, "bad indentation of a mapping entry")
+		}
+		if p.atSequenceEntry() {
+			return nil, p.errorf(path, p.offset, "block sequence entries are not allowed in this context")
+		}
+	}
+
+	// explicit keys take precedence over merged ones, and earlier merge sources over later ones.
+	for _, merged := range merges {
+		for k, v := range merged {
+			if _, ok := object[k]; !ok {
+				object[k] = v
+			}
+		}
+	}
+	return object, nil
+}
+
+// mergeSources returns the mappings referenced by a merge key ("<<") value.
+func (p *yamlParser) mergeSources(path jsonpath, offset int, v jsonvalue) ([]map[string]any, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		return []map[string]any{t}, nil
+	case []any:
+		sources := []map[string]any{}
+		for _, elem := range t {
+			m, ok := elem.(map[string]any)
+			if !ok {
+				return nil, p.errorf(path, offset, "merge key value must be a mapping or a sequence of mappings")
+			}
+			sources = append(sources, m)
+		}
+		return sources, nil
+	default:
+		return nil, p.errorf(path, offset, "merge key value must be a mapping or a sequence of mappings")
+	}
+}
+
+// parseMappingKey parses an implicit mapping key and returns it as a string.
+// Plain keys are used as written, so that for example the key 1.0 stays "1.0".
+// isMerge reports whether the key is the merge key "<<".
+func (p *yamlParser) parseMappingKey(path jsonpath) (key string, isMerge bool, err error) {
+	if p.peek() == '*' {
+		v, err := p.parseAlias(path)
+		if err != nil {
+			return "", false, err
+		}
+		return p.keyString(v), false, nil
+	}
+
+	s, plain, err := p.parseScalar(p.column(), path, false)
+	if err != nil {
+		return "", false, err
+	}
+	return s, plain && s == "<<", nil
+}
+
+// keyString converts a scalar used as a mapping key to a string, since JSON object keys are strings.
+func (p *yamlParser) keyString(v jsonvalue) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case nil:
+		return "null"
+	default:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+}
+
+// isMappingKey reports whether the current line continues with an implicit mapping key followed by ':'.
+func (p *yamlParser) isMappingKey() bool {
+	i := p.offset
+
+	switch p.peek() {
+	case '"', '\'':
+		quote := p.peek()
+		for i++; i < len(p.data) && p.data[i] != '\n'; i++ {
+			if p.data[i] == '\\' && quote == '"' {
+				i++
+				continue
+			}
+			if p.data[i] == quote {
+				if quote == '\'' && i+1 < len(p.data) && p.data[i+1] == '\'' {
+					i++
+					continue
+				}
+				break
+			}
+		}
+		if i >= len(p.data) || p.data[i] != quote {
+			return false
+		}
+		i++
+		for i < len(p.data) && (p.data[i] == ' ' || p.data[i] == '\t') {
+			i++
+		}
+		return i < len(p.data) && p.data[i] == ':' && isYAMLBreakOrSpace(p.data, i+1)
+
+	case '[', '{', '|', '>', '#', '&', '!', '%', '@', '`':
+		return false
+	}
+
+	for ; i < len(p.data) && p.data[i] != '\n'; i++ {
+		switch p.data[i] {
+		case ':':
+			if isYAMLBreakOrSpace(p.data, i+1) {
+				return true
+			}
+		case '#':
+			if i > p.offset && (p.data[i-1] == ' ' || p.data[i-1] == '\t') {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// parseScalar parses a plain or quoted scalar and returns its text.
+// Continuation lines of a multi-line scalar must be indented more than parentIndent.
+func (p *yamlParser) parseScalar(parentIndent int, path jsonpath, flow bool) (s string, plain bool, err error) {
+	switch p.peek() {
+	case '\'':
+		s, err := p.parseSingleQuoted(path)
+		return s, false, err
+	case '"':
+		s, err := p.parseDoubleQuoted(path)
+		return s, false, err
+	default:
+		s, err := p.parsePlain(parentIndent, path, flow)
+		return s, true, err
+	}
+}
+
+func (p *yamlParser) parsePlain(parentIndent int, path jsonpath, flow bool) (string, error) {
+	start := p.offset
+	switch c := p.peek(); {
+	case c == '@' || c == '`':
+		return "", p.errorf(path, start, "found character %q that cannot start any token", c)
+	case c == '%' && p.column() == 0:
+		return "", p.errorf(path, start, "unexpected directive")
+	case (c == '-' || c == '?' || c == ':') && p.atIndicator(1):
+		return "", p.errorf(path, start, "unexpected %q indicator", c)
+	}
+
+	sb := strings.Builder{}
+	for {
+		lineStart := p.offset
+		for !p.eof() && p.peek() != '\n' {
+			c := p.peek()
+			if c == ':' && (isYAMLBreakOrSpace(p.data, p.offset+1) || flow && isFlowIndicator(p.data, p.offset+1)) {
+				break
+			}
+			if c == '#' && p.offset > lineStart && (p.data[p.offset-1] == ' ' || p.data[p.offset-1] == '\t') {
+				break
+			}
+			if flow && (c == ',' || c == '[' || c == ']' || c == '{' || c == '}') {
+				break
+			}
+			p.offset++
+		}
+		sb.WriteString(strings.TrimRight(string(p.data[lineStart:p.offset]), " \t\r"))
+
+		if p.eof() || p.peek() != '\n' {
+			break
+		}
+
+		// look ahead for a continuation line
+		save, saveLineStart := p.offset, p.lineStart
+		breaks := 0
+		for !p.eof() && p.peek() == '\n' {
+			p.newline()
+			breaks++
+			p.skipInlineSpace()
+		}
+		if p.eof() || p.peek() == '#' || p.hasMarker("---") || p.hasMarker("...") ||
+			(!flow && p.column() <= parentIndent) ||
+			(!flow && p.isMappingKey()) ||
+			(flow && isFlowIndicator(p.data, p.offset)) {
+			p.offset, p.lineStart = save, saveLineStart
+			break
+		}
+
+		if breaks == 1 {
+			sb.WriteByte(' ')
+		} else {
+			sb.WriteString(strings.Repeat("\n", breaks-1))
+		}
+	}
+
+	return strings.TrimRight(sb.String(), " \t"), nil
+}
+
+func (p *yamlParser) parseSingleQuoted(path jsonpath) (string, error) {
+	start := p.offset
+	p.offset++ // '
+
+	sb := strings.Builder{}
+	for {
+		if p.eof() {
+			return "", p.errorf(path, start, "found unexpected end of stream while scanning a quoted scalar")
+		}
+
+		switch c := p.peek(); c {
+		case '\'':
+			if p.offset+1 < len(p.data) && p.data[p.offset+1] == '\'' {
+				sb.WriteByte('\'')
+				p.offset += 2
+				continue
+			}
+			p.offset++
+			return sb.String(), nil
+		case '\n':
+			p.foldQuotedLines(&sb)
+		default:
+			sb.WriteByte(c)
+			p.offset++
+		}
+	}
+}
+
+func (p *yamlParser) parseDoubleQuoted(path jsonpath) (string, error) {
+	start := p.offset
+	p.offset++ // "
+
+	sb := strings.Builder{}
+	for {
+		if p.eof() {
+			return "", p.errorf(path, start, "found unexpected end of stream while scanning a quoted scalar")
+		}
+
+		switch c := p.peek(); c {
+		case '"':
+			p.offset++
+			return sb.String(), nil
+		case '\n':
+			p.foldQuotedLines(&sb)
+		case '\\':
+			if err := p.parseDoubleQuotedEscape(path, &sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(c)
+			p.offset++
+		}
+	}
+}
+
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f",
+	'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\",
+	'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029",
+}
+
+func (p *yamlParser) parseDoubleQuotedEscape(path jsonpath, sb *strings.Builder) error {
+	start := p.offset
+	p.offset++ // '\'
+	if p.eof() {
+		return p.errorf(path, start, "found unexpected end of stream while scanning a quoted scalar")
+	}
+
+	c := p.peek()
+	p.offset++
+
+	if s, ok := yamlEscapes[c]; ok {
+		sb.WriteString(s)
+		return nil
+	}
+
+	digits := 0
+	switch c {
+	case '\n':
+		// escaped line break: join the lines without a space
+		p.offset--
+		p.newline()
+		p.skipInlineSpace()
+		return nil
+	case 'x':
+		digits = 2
+	case 'u':
+		digits = 4
+	case 'U':
+		digits = 8
+	default:
+		return p.errorf(path, start, "found unknown escape character %q while parsing a quoted scalar", c)
+	}
+
+	if p.offset+digits > len(p.data) {
+		return p.errorf(path, start, "invalid escape sequence in quoted scalar")
+	}
+	v, err := strconv.ParseUint(string(p.data[p.offset:p.offset+digits]), 16, 32)
+	if err != nil || !utf8.ValidRune(rune(v)) {
+		return p.errorf(path, start, "invalid escape sequence %q in quoted scalar", string(p.data[start:p.offset+digits]))
+	}
+	p.offset += digits
+	sb.WriteRune(rune(v))
+	return nil
+}
+
+// foldQuotedLines folds the line breaks in a multi-line quoted scalar:
+// a single line break becomes a space, and each following empty line becomes a line feed.
+func (p *yamlParser) foldQuotedLines(sb *strings.Builder) {
+	trimmed := strings.TrimRight(sb.String(), " \t")
+	sb.Reset()
+	sb.WriteString(trimmed)
+
+	breaks := 0
+	for !p.eof() && p.peek() == '\n' {
+		p.newline()
+		breaks++
+		p.skipInlineSpace()
+	}
+
+	if breaks == 1 {
+		sb.WriteByte(' ')
+	} else {
+		sb.WriteString(strings.Repeat("\n", breaks-1))
+	}
+}
+
+func (p *yamlParser) parseBlockScalar(parentIndent int, path jsonpath) (string, error) {
+	folded := p.peek() == '>'
+	p.offset++
+
+	chomping := byte(0) // 0: clip, '-': strip, '+': keep
+	indent := -1        // unknown until the first non-empty line, unless given explicitly
+	for i := 0; i < 2 && !p.eof(); i++ {
+		switch c := p.peek(); {
+		case (c == '-' || c == '+') && chomping == 0:
+			chomping = c
+			p.offset++
+		case '1' <= c && c <= '9' && indent < 0:
+			indent = max(parentIndent, 0) + int(c-'0')
+			p.offset++
+		}
+	}
+
+	p.skipInlineSpace()
+	if p.peek() == '#' {
+		p.skipLine()
+	}
+	if !p.eof() && p.peek() != '\n' {
+		return "", p.errorf(path, p.offset, "did not find expected comment or line break after block scalar header")
+	}
+	if p.eof() {
+		return "", nil
+	}
+	p.newline()
+
+	lines := []string{}
+	for !p.eof() {
+		lineEnd := bytes.IndexByte(p.data[p.offset:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(p.data) - p.offset
+		}
+		line := strings.TrimSuffix(string(p.data[p.offset:p.offset+lineEnd]), "\r")
+		spaces := len(line) - len(strings.TrimLeft(line, " "))
+
+		if spaces == len(line) {
+			// empty lines belong to the scalar until a less indented line is found
+			if indent >= 0 && spaces > indent {
+				lines = append(lines, line[indent:])
+			} else {
+				lines = append(lines, "")
+			}
+		} else {
+			if p.hasMarker("---") || p.hasMarker("...") {
+				break
+			}
+			if indent < 0 {
+				if spaces <= parentIndent {
+					break
+				}
+				indent = spaces
+			}
+			if spaces < indent {
+				break
+			}
+			lines = append(lines, line[indent:])
+		}
+
+		p.offset += lineEnd
+		if p.eof() {
+			break
+		}
+		p.newline()
+	}
+
+	// trailing empty lines are handled by the chomping indicator
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var content string
+	if folded {
+		content = foldBlockLines(lines)
+	} else {
+		content = strings.Join(lines, "\n")
+	}
+
+	switch {
+	case chomping == '-':
+		return content, nil
+	case chomping == '+' && len(lines) == 0:
+		return strings.Repeat("\n", trailing), nil
+	case chomping == '+':
+		return content + "\n" + strings.Repeat("\n", trailing), nil
+	case len(lines) == 0:
+		return "", nil
+	default:
+		return content + "\n", nil
+	}
+}
+
+// foldBlockLines joins the lines of a folded block scalar.
+// Line breaks between two normal lines are folded into a space,
+// while more-indented lines and empty lines keep their line breaks.
+func foldBlockLines(lines []string) string {
+	sb := strings.Builder{}
+
+	prevNormal := false
+	breaks := 0
+	first := true
+	for _, line := range lines {
+		if line == "" {
+			breaks++
+			continue
+		}
+
+		normal := line[0] != ' ' && line[0] != '\t'
+		switch {
+		case first:
+			sb.WriteString(strings.Repeat("\n", breaks))
+		case prevNormal && normal && breaks == 0:
+			sb.WriteByte(' ')
+		case prevNormal && normal:
+			sb.WriteString(strings.Repeat("\n", breaks))
+		default:
+			sb.WriteString(strings.Repeat("\n", breaks+1))
+		}
+		sb.WriteString(line)
+
+		first = false
+		prevNormal = normal
+		breaks = 0
+	}
+	return sb.String()
+}
+
+func (p *yamlParser) parseFlowNode(path jsonpath) (jsonvalue, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxParseDepth {
+		return nil, p.errorf(path, p.offset, "exceeded max depth")
+	}
+
+	if err := p.skipSpace(path); err != nil {
+		return nil, err
+	}
+
+	anchor, tag, err := p.parseProperties(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.skipSpace(path); err != nil {
+		return nil, err
+	}
+
+	start := p.offset
+	var v jsonvalue
+	switch p.peek() {
+	case '[':
+		v, err = p.parseFlowSequence(path)
+	case '{':
+		v, err = p.parseFlowMapping(path)
+	case '*':
+		v, err = p.parseAlias(path)
+	case ',', ']', '}':
+		v, err = p.resolveTagged(path, start, tag, "", true)
+	default:
+		var s string
+		var plain bool
+		s, plain, err = p.parseScalar(-1, path, true)
+		if err == nil {
+			v, err = p.resolveTagged(path, start, tag, s, plain)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.setAnchor(anchor, v)
+	return v, nil
+}
+
+func (p *yamlParser) parseFlowSequence(path jsonpath) (jsonvalue, error) {
+	start := p.offset
+	p.offset++ // '['
+	array := []any{}
+
+	for {
+		if err := p.skipSpace(path); err != nil {
+			return nil, err
+		}
+		if p.eof() {
+			return nil, p.errorf(path, start, "did not find expected ',' or ']'")
+		}
+		if p.peek() == ']' {
+			p.offset++
+			return array, nil
+		}
+
+		elemPath := path.append(arrayIndex(len(array)))
+		v, err := p.parseFlowNode(elemPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.skipSpace(path); err != nil {
+			return nil, err
+		}
+		if p.peek() == ':' {
+			// single pair mapping, e.g. [key: value]
+			p.offset++
+			value, err := p.parseFlowNode(elemPath)
+			if err != nil {
+				return nil, err
+			}
+			v = map[string]any{p.keyString(v): value}
+			if err := p.skipSpace(path); err != nil {
+				return nil, err
+			}
+		}
+		array = append(array, v)
+
+		switch p.peek() {
+		case ',':
+			p.offset++
+		case ']':
+		default:
+			return nil, p.errorf(path, p.offset, "did not find expected ',' or ']'")
+		}
+	}
+}
+
+func (p *yamlParser) parseFlowMapping(path jsonpath) (jsonvalue, error) {
+	start := p.offset
+	p.offset++ // '{'
+	object := map[string]any{}
+
+	for {
+		if err := p.skipSpace(path); err != nil {
+			return nil, err
+		}
+		if p.eof() {
+			return nil, p.errorf(path, start, "did not find expected ',' or '}'")
+		}
+		if p.peek() == '}' {
+			p.offset++
+			return object, nil
+		}
+
+		keyStart := p.offset
+		key, err := p.parseFlowKey(path)
+		if err != nil {
+			return nil, err
+		}
+		elemPath := path.append(objectKey(key))
+
+		if err := p.skipSpace(elemPath); err != nil {
+			return nil, err
+		}
+		var v jsonvalue
+		if p.peek() == ':' {
+			p.offset++
+			if v, err = p.parseFlowNode(elemPath); err != nil {
+				return nil, err
+			}
+			if err := p.skipSpace(path); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, ok := object[key]; ok {
+			return nil, p.errorf(elemPath, keyStart, "mapping key %q already defined", key)
+		}
+		object[key] = v
+
+		switch p.peek() {
+		case ',':
+			p.offset++
+		case '}':
+		default:
+			return nil, p.errorf(path, p.offset, "did not find expected ',' or '}'")
+		}
+	}
+}
+
+func (p *yamlParser) parseFlowKey(path jsonpath) (string, error) {
+	switch p.peek() {
+	case '*':
+		v, err := p.parseAlias(path)
+		if err != nil {
+			return "", err
+		}
+		return p.keyString(v), nil
+	case '[', '{':
+		return "", p.errorf(path, p.offset, "complex mapping keys are not supported")
+	default:
+		s, _, err := p.parseScalar(-1, path, true)
+		return s, err
+	}
+}
+
+// parseProperties parses the optional anchor and tag of a node.
+func (p *yamlParser) parseProperties(path jsonpath) (anchor, tag string, err error) {
+	for {
+		switch p.peek() {
+		case '&':
+			if anchor != "" {
+				return "", "", p.errorf(path, p.offset, "found duplicate anchor")
+			}
+			p.offset++
+			anchor = p.scanName()
+			if anchor == "" {
+				return "", "", p.errorf(path, p.offset, "did not find expected alphabetic or numeric character")
+			}
+		case '!':
+			if tag != "" {
+				return "", "", p.errorf(path, p.offset, "found duplicate tag")
+			}
+			start := p.offset
+			for !p.eof() && !isYAMLBreakOrSpace(p.data, p.offset) && !isFlowIndicator(p.data, p.offset) {
+				p.offset++
+			}
+			tag = string(p.data[start:p.offset])
+		default:
+			return anchor, tag, nil
+		}
+		p.skipInlineSpace()
+	}
+}
+
+func (p *yamlParser) parseAlias(path jsonpath) (jsonvalue, error) {
+	start := p.offset
+	p.offset++ // '*'
+
+	name := p.scanName()
+	v, ok := p.anchors[name]
+	if !ok {
+		return nil, p.errorf(path, start, "found undefined alias %q", name)
+	}
+	return v, nil
+}
+
+func (p *yamlParser) setAnchor(anchor string, v jsonvalue) {
+	if anchor != "" {
+		p.anchors[anchor] = v
+	}
+}
+
+// scanName scans an anchor or alias name.
+func (p *yamlParser) scanName() string {
+	start := p.offset
+	for !p.eof() && !isYAMLBreakOrSpace(p.data, p.offset) && !isFlowIndicator(p.data, p.offset) {
+		p.offset++
+	}
+	return string(p.data[start:p.offset])
+}
+
+var (
+	yamlIntPattern   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloatPattern = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// resolveTagged resolves the type of a scalar with an optional tag.
+func (p *yamlParser) resolveTagged(path jsonpath, offset int, tag string, s string, plain bool) (jsonvalue, error) {
+	switch tag {
+	case "", "!!int", "!!float", "!!bool", "!!null":
+		if tag == "" && !plain {
+			return s, nil
+		}
+		v, err := p.resolvePlain(path, offset, s)
+		if err != nil {
+			return nil, err
+		}
+		if tag != "" && tag != "!!"+yamlTypeName(v) && !(tag == "!!float" && isNumber(v)) {
+			return nil, p.errorf(path, offset, "cannot decode %q as %s", s, tag)
+		}
+		return v, nil
+	case "!!str", "!":
+		return s, nil
+	default:
+		// other tags (e.g. !!binary or application-specific ones) keep the scalar as is
+		if plain {
+			return p.resolvePlain(path, offset, s)
+		}
+		return s, nil
+	}
+}
+
+// resolvePlain resolves the type of a plain scalar by the YAML 1.2 core schema.
+func (p *yamlParser) resolvePlain(path jsonpath, offset int, s string) (jsonvalue, error) {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF", "-.inf", "-.Inf", "-.INF", ".nan", ".NaN", ".NAN":
+		return nil, p.errorf(path, offset, "%s cannot be represented in JSON", s)
+	}
+
+	var literal string
+	switch {
+	case strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0o"):
+		base := 16
+		if s[1] == 'o' {
+			base = 8
+		}
+		i, ok := new(big.Int).SetString(s[2:], base)
+		if !ok {
+			return s, nil
+		}
+		literal = i.String()
+	case yamlIntPattern.MatchString(s):
+		i, _ := new(big.Int).SetString(strings.TrimPrefix(s, "+"), 10)
+		literal = i.String()
+	case yamlFloatPattern.MatchString(s):
+		literal = normalizeYAMLFloat(s)
+	default:
+		return s, nil
+	}
+
+	v, err := newNumber(literal, p.useNumber)
+	if err != nil {
+		return nil, p.errorf(path, offset, "cannot unmarshal number %s into Go value of type float64", s)
+	}
+	return v, nil
+}
+
+// normalizeYAMLFloat converts a YAML float literal (e.g. "+.5" or "1.") to a JSON number literal.
+func normalizeYAMLFloat(s string) string {
+	sign := ""
+	switch s[0] {
+	case '-':
+		sign, s = "-", s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	mantissa, exp, hasExp := strings.Cut(strings.ToLower(s), "e")
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	literal := sign + intPart
+	if fracPart != "" {
+		literal += "." + fracPart
+	}
+	if hasExp {
+		literal += "e" + exp
+	}
+	return literal
+}
+
+func yamlTypeName(v jsonvalue) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case string:
+		return "str"
+	default:
+		if isNumber(v) {
+			return "int"
+		}
+		return getTypeString(v)
+	}
+}
+
+// skipSpace skips whitespace, line breaks and comments.
+func (p *yamlParser) skipSpace(path jsonpath) error {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\r':
+			p.offset++
+		case '\t':
+			if p.atLineContentStart() {
+				// tabs may separate tokens, but cannot be used for indentation.
+				rest := bytes.TrimLeft(p.data[p.offset:], " \t\r")
+				if len(rest) > 0 && rest[0] != '\n' && rest[0] != '#' {
+					return p.errorf(path, p.offset, "found a tab character that violates indentation")
+				}
+			}
+			p.offset++
+		case '\n':
+			p.newline()
+		case '#':
+			if p.offset > p.lineStart && p.data[p.offset-1] != ' ' && p.data[p.offset-1] != '\t' {
+				return nil
+			}
+			p.skipLine()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (p *yamlParser) skipInlineSpace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\r') {
+		p.offset++
+	}
+}
+
+// skipLine skips to the line break at the end of the current line.
+func (p *yamlParser) skipLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.offset++
+	}
+}
+
+func (p *yamlParser) newline() {
+	p.offset++
+	p.lineStart = p.offset
+}
+
+func (p *yamlParser) eof() bool {
+	return p.offset >= len(p.data)
+}
+
+func (p *yamlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.data[p.offset]
+}
+
+func (p *yamlParser) column() int {
+	return p.offset - p.lineStart
+}
+
+// atLineContentStart reports whether only indentation precedes the current position on its line.
+func (p *yamlParser) atLineContentStart() bool {
+	for i := p.lineStart; i < p.offset; i++ {
+		if p.data[i] != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+// atSequenceEntry reports whether the current position is at a block sequence entry indicator.
+func (p *yamlParser) atSequenceEntry() bool {
+	return p.peek() == '-' && p.atIndicator(1)
+}
+
+// atIndicator reports whether the byte at the given distance from the current position ends an indicator.
+func (p *yamlParser) atIndicator(distance int) bool {
+	return isYAMLBreakOrSpace(p.data, p.offset+distance)
+}
+
+// hasMarker reports whether the current position is at the given document marker ("---" or "...").
+func (p *yamlParser) hasMarker(marker string) bool {
+	return p.column() == 0 &&
+		bytes.HasPrefix(p.data[p.offset:], []byte(marker)) &&
+		isYAMLBreakOrSpace(p.data, p.offset+len(marker))
+}
+
+func (p *yamlParser) errorf(path jsonpath, offset int, format string, args ...any) error {
+	line, column := lineColumn(p.data, offset)
+	return newSyntaxError(path, line, column, fmt.Sprintf(format, args...))
+}
+
+func isYAMLBreakOrSpace(data []byte, i int) bool {
+	return i >= len(data) || data[i] == ' ' || data[i] == '\t' || data[i] == '\r' || data[i] == '\n'
+}
+
+func isFlowIndicator(data []byte, i int) bool {
+	return i < len(data) && (data[i] == ',' || data[i] == '[' || data[i] == ']' || data[i] == '{' || data[i] == '}')
+}
+
+// MarshalYAML marshals the Node's value into a YAML document in block style.
+// Object keys are sorted, and strings are quoted whenever they would otherwise be read as another type.
+func (n *Node) MarshalYAML() ([]byte, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeYAML(buf, n.path, n.value, 0, false); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeYAML writes v at the given indentation.
+// If compact is set, the first line continues the current line (after "- ").
+func writeYAML(buf *bytes.Buffer, path jsonpath, v jsonvalue, indent int, compact bool) error {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) == 0 {
+			break
+		}
+
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for i, k := range keys {
+			if i > 0 || !compact {
+				buf.WriteString(strings.Repeat(" ", indent))
+			}
+			buf.WriteString(formatYAMLString(k))
+			buf.WriteByte(':')
+
+			elemPath := path.append(objectKey(k))
+			if isYAMLBlockCollection(t[k]) {
+				buf.WriteByte('\n')
+				if err := writeYAML(buf, elemPath, t[k], indent+2, false); err != nil {
+					return err
+				}
+				continue
+			}
+			buf.WriteByte(' ')
+			if err := writeYAMLScalar(buf, elemPath, t[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case []any:
+		if len(t) == 0 {
+			break
+		}
+
+		for i, elem := range t {
+			if i > 0 || !compact {
+				buf.WriteString(strings.Repeat(" ", indent))
+			}
+			buf.WriteString("- ")
+
+			elemPath := path.append(arrayIndex(i))
+			if isYAMLBlockCollection(elem) {
+				if err := writeYAML(buf, elemPath, elem, indent+2, true); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := writeYAMLScalar(buf, elemPath, elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !compact {
+		buf.WriteString(strings.Repeat(" ", indent))
+	}
+	return writeYAMLScalar(buf, path, v)
+}
+
+// writeYAMLScalar writes a scalar or an empty collection, followed by a line break.
+func writeYAMLScalar(buf *bytes.Buffer, path jsonpath, v jsonvalue) error {
+	switch t := v.(type) {
+	case string:
+		buf.WriteString(formatYAMLString(t))
+	case []any:
+		buf.WriteString("[]")
+	case map[string]any:
+		buf.WriteString("{}")
+	default:
+		data, err := marshal(path, v)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte('\n')
+	return nil
+}
+
+func isYAMLBlockCollection(v jsonvalue) bool {
+	switch t := v.(type) {
+	case map[string]any:
+		return len(t) > 0
+	case []any:
+		return len(t) > 0
+	default:
+		return false
+	}
+}
+
+// formatYAMLString returns s as a plain scalar if it is read back as the same string,
+// also by YAML 1.1 consumers, or as a double-quoted scalar otherwise.
+func formatYAMLString(s string) string {
+	if isYAMLPlainSafe(s) {
+		return s
+	}
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// yaml11Bools are the plain scalars that YAML 1.1 reads as booleans but YAML 1.2 reads as strings.
+// They are quoted, so that tools still using YAML 1.1, such as many Kubernetes tools, read them as strings.
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true,
+	"on": true, "On": true, "ON": true,
+	"off": true, "Off": true, "OFF": true,
+}
+
+func isYAMLPlainSafe(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) {
+		return false
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`.+0123456789~") {
+		return false
+	}
+	if s == "<<" || strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	if yaml11Bools[s] {
+		return false
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f || r == 0x85 || r == 0xfeff || r == '\u2028' || r == '\u2029' {
+			return false
+		}
+	}
+
+	p := &yamlParser{}
+	v, err := p.resolvePlain(nil, 0, s)
+	return err == nil && v == s
+}
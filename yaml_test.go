@@ -0,0 +1,367 @@
+package jsond
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "block collections",
+			src: `
+# a comment
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels: {app: web, tier: "frontend"}
+spec:
+  replicas: 3
+  ports:
+  - 80
+  - 443
+  containers:
+    - name: web
+      image: "nginx:1.25"
+      args: [--port, "8080"]
+    -   name: sidecar
+        env:
+          - {name: DEBUG, value: 'true'}
+`,
+			want: `{
+				"apiVersion": "apps/v1",
+				"kind": "Deployment",
+				"metadata": {"name": "web", "labels": {"app": "web", "tier": "frontend"}},
+				"spec": {
+					"replicas": 3,
+					"ports": [80, 443],
+					"containers": [
+						{"name": "web", "image": "nginx:1.25", "args": ["--port", "8080"]},
+						{"name": "sidecar", "env": [{"name": "DEBUG", "value": "true"}]}
+					]
+				}
+			}`,
+		},
+		{
+			name: "core schema",
+			src: `
+nulls: [~, null, Null, ]
+empty:
+bools: [true, False, TRUE]
+ints: [0, -12, +7, 0x1F, 0o17]
+floats: [1.5, -.5, 1e3, 2.]
+strings: [yes, no, on, 1.2.3, "123", '~', 12:30]
+tagged: [!!str 123, !!int "42", !!float 1, !!str]
+keys: {1.0: a, true: b}
+`,
+			want: `{
+				"nulls": [null, null, null],
+				"empty": null,
+				"bools": [true, false, true],
+				"ints": [0, -12, 7, 31, 15],
+				"floats": [1.5, -0.5, 1000, 2],
+				"strings": ["yes", "no", "on", "1.2.3", "123", "~", "12:30"],
+				"tagged": ["123", 42, 1, ""],
+				"keys": {"1.0": "a", "true": "b"}
+			}`,
+		},
+		{
+			name: "scalars",
+			src: `
+plain: multi
+  line
+
+  plain
+single: 'it''s
+  folded'
+double: "tab\tnew\nline \u00e9 \x41 \
+  joined"
+literal: |
+  line 1
+    indented
+  line 3
+
+folded: >
+  folded
+  text
+
+  new paragraph
+    more indented
+  end
+strip: |-
+  text
+
+keep: |+
+  text
+
+indent: |2
+    two
+url: http://example.com:8080/path#fragment
+comment: value # a comment
+`,
+			want: `{
+				"plain": "multi line\nplain",
+				"single": "it's folded",
+				"double": "tab\tnew\nline é A joined",
+				"literal": "line 1\n  indented\nline 3\n",
+				"folded": "folded text\nnew paragraph\n  more indented\nend\n",
+				"strip": "text",
+				"keep": "text\n\n",
+				"indent": "  two\n",
+				"url": "http://example.com:8080/path#fragment",
+				"comment": "value"
+			}`,
+		},
+		{
+			name: "anchors and merge keys",
+			src: `
+defaults: &defaults
+  adapter: postgres
+  host: localhost
+development:
+  <<: *defaults
+  database: dev
+test:
+  <<: [*defaults, {port: 5432}]
+  host: db
+hosts: &hosts [a, b]
+all: *hosts
+`,
+			want: `{
+				"defaults": {"adapter": "postgres", "host": "localhost"},
+				"development": {"adapter": "postgres", "host": "localhost", "database": "dev"},
+				"test": {"adapter": "postgres", "host": "db", "port": 5432},
+				"hosts": ["a", "b"],
+				"all": ["a", "b"]
+			}`,
+		},
+		{
+			name: "nested sequences",
+			src: `
+- - a
+  - b
+-
+  - c
+- key: value
+  other:
+  - d
+`,
+			want: `[["a", "b"], ["c"], {"key": "value", "other": ["d"]}]`,
+		},
+		{
+			name: "json",
+			src:  `{"a": [1, 2.5, "x", null, true], "b": {"c": {}}, "d": []}`,
+			want: `{"a": [1, 2.5, "x", null, true], "b": {"c": {}}, "d": []}`,
+		},
+		{
+			name: "document markers",
+			src: `%YAML 1.2
+---
+text
+...
+`,
+			want: `"text"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseYAML([]byte(tt.src))
+			if got.err != nil {
+				t.Fatal(got.err)
+			}
+
+			want := Parse([]byte(tt.want))
+			if want.err != nil {
+				t.Fatal(want.err)
+			}
+			if !reflect.DeepEqual(got.value, want.value) {
+				t.Errorf("\ngot  %#v\nwant %#v", got.value, want.value)
+			}
+		})
+	}
+}
+
+func TestParseYAMLStream(t *testing.T) {
+	src := `
+a: 1
+---
+- b
+--- c
+---
+`
+	nodes, err := ParseYAMLStream([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wants := []string{`{"a":1}`, `["b"]`, `"c"`, `null`}
+	if len(nodes) != len(wants) {
+		t.Fatalf("\ngot  %d documents\nwant %d", len(nodes), len(wants))
+	}
+	for i, want := range wants {
+		got, _ := nodes[i].Marshal()
+		if string(got) != want {
+			t.Errorf("\ngot  %s\nwant %s", got, want)
+		}
+	}
+
+	if err := ParseYAML([]byte(src)).Error(); err == nil {
+		t.Errorf("ParseYAML accepted multiple documents")
+	}
+}
+
+func TestParseYAMLError(t *testing.T) {
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "bad indentation",
+			src:  "spec:\n  containers:\n    - name: a\n     image: b\n",
+			want: "line 4, column 6: bad indentation of a sequence entry at $['spec']['containers']",
+		},
+		{
+			name: "undefined alias",
+			src:  "a:\n  b: *missing\n",
+			want: "line 2, column 6: found undefined alias \"missing\" at $['a']['b']",
+		},
+		{
+			name: "duplicate key",
+			src:  "a: 1\nb:\n  c: 1\n  c: 2\n",
+			want: "line 4, column 3: mapping key \"c\" already defined at $['b']['c']",
+		},
+		{
+			name: "mapping in scalar",
+			src:  "items:\n- a: b: c\n",
+			want: "line 2, column 6: mapping values are not allowed in this context at $['items'][0]['a']",
+		},
+		{
+			name: "unterminated flow",
+			src:  "a: [1, 2\nb: 3\n",
+			want: "line 3, column 1: did not find expected ',' or ']' at $['a']",
+		},
+		{
+			name: "nan",
+			src:  "a: [.nan]\n",
+			want: "line 1, column 5: .nan cannot be represented in JSON at $['a'][0]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ParseYAML([]byte(tt.src)).Error()
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("\ngot  %v\nwant %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	src := `{
+		"name": "web",
+		"replicas": 3,
+		"ratio": 0.5,
+		"enabled": true,
+		"none": null,
+		"quoted": ["true", "123", "", " padded", "a: b", "- x", "line\nbreak", "<<"],
+		"empty": {"array": [], "object": {}},
+		"containers": [
+			{"name": "web", "ports": [80, 443]},
+			[1, [2]]
+		]
+	}`
+	want := `containers:
+  - name: web
+    ports:
+      - 80
+      - 443
+  - - 1
+    - - 2
+empty:
+  array: []
+  object: {}
+enabled: true
+name: web
+none: null
+quoted:
+  - "true"
+  - "123"
+  - ""
+  - " padded"
+  - "a: b"
+  - "- x"
+  - "line\nbreak"
+  - "<<"
+ratio: 0.5
+replicas: 3
+`
+
+	node := Parse([]byte(src))
+	got, err := node.MarshalYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("\ngot\n%s\nwant\n%s", got, want)
+	}
+
+	roundTrip := ParseYAML(got)
+	if roundTrip.err != nil {
+		t.Fatal(roundTrip.err)
+	}
+	if !reflect.DeepEqual(roundTrip.value, node.value) {
+		t.Errorf("\ngot  %#v\nwant %#v", roundTrip.value, node.value)
+	}
+}
+
+func TestMarshalYAMLBoolWords(t *testing.T) {
+	// YAML 1.1 reads these plain scalars as booleans, so they are quoted
+	src := `{"on": ["y", "Y", "yes", "Yes", "YES", "n", "N", "no", "No", "NO", "on", "On", "ON", "off", "Off", "OFF"], "plain": ["yeah", "nope", "onward", "oN"]}`
+	want := `"on":
+  - "y"
+  - "Y"
+  - "yes"
+  - "Yes"
+  - "YES"
+  - "n"
+  - "N"
+  - "no"
+  - "No"
+  - "NO"
+  - "on"
+  - "On"
+  - "ON"
+  - "off"
+  - "Off"
+  - "OFF"
+plain:
+  - yeah
+  - nope
+  - onward
+  - oN
+`
+
+	node := Parse([]byte(src))
+	got, err := node.MarshalYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("\ngot\n%s\nwant\n%s", got, want)
+	}
+
+	roundTrip := ParseYAML(got)
+	if !reflect.DeepEqual(roundTrip.value, node.value) {
+		t.Errorf("\ngot  %#v\nwant %#v", roundTrip.value, node.value)
+	}
+}